@@ -0,0 +1,136 @@
+package dgo2poc
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Query for Client.GuildAuditLog(). Only one of Before and After may be set.
+// Limit is the max number of entries to return in total.
+type AuditLogQuery struct {
+	UserID     string
+	ActionType discordgo.AuditLogAction
+	Before     string
+	After      string
+	Limit      int
+}
+
+// Returns the filters as query parameters; Before, After and Limit are handled by the iterator.
+func (q AuditLogQuery) values() url.Values {
+	v := url.Values{}
+	if q.UserID != "" {
+		v.Set("user_id", q.UserID)
+	}
+	if q.ActionType != 0 {
+		v.Set("action_type", strconv.Itoa(int(q.ActionType)))
+	}
+	return v
+}
+
+// Options for Client.GuildAuditLog().
+type AuditLogOpt func(q *AuditLogQuery)
+
+// Only return entries for actions taken by the given user.
+func AuditLogByUser(uid string) AuditLogOpt {
+	return AuditLogOpt(func(q *AuditLogQuery) {
+		q.UserID = uid
+	})
+}
+
+// Only return entries of the given type, eg. discordgo.AuditLogActionMemberBanAdd.
+func AuditLogByAction(action discordgo.AuditLogAction) AuditLogOpt {
+	return AuditLogOpt(func(q *AuditLogQuery) {
+		q.ActionType = action
+	})
+}
+
+// Return entries before the given entry ID.
+func AuditLogBefore(id string) AuditLogOpt {
+	return AuditLogOpt(func(q *AuditLogQuery) {
+		q.Before = id
+	})
+}
+
+// Return entries after the given entry ID, oldest first.
+func AuditLogAfter(id string) AuditLogOpt {
+	return AuditLogOpt(func(q *AuditLogQuery) {
+		q.After = id
+	})
+}
+
+// Return at most n entries in total. By default, there's no limit.
+func AuditLogLimit(n int) AuditLogOpt {
+	return AuditLogOpt(func(q *AuditLogQuery) {
+		q.Limit = n
+	})
+}
+
+func (c *client) GuildAuditLog(ctx context.Context, gid string, opts ...AuditLogOpt) *AuditLogIterator {
+	var q AuditLogQuery
+	for _, opt := range opts {
+		opt(&q)
+	}
+	param, start := "before", q.Before
+	if q.After != "" {
+		param, start = "after", q.After
+	}
+
+	// Entries are wrapped in an object, alongside the users, webhooks, etc. they reference.
+	it := &AuditLogIterator{
+		users:    make(map[string]*discordgo.User),
+		webhooks: make(map[string]*discordgo.Webhook),
+	}
+	urlStr := c.BaseURL + EndpointGuildAuditLogs(gid)
+	get := func(ctx context.Context, urlStr string) ([]*discordgo.AuditLogEntry, error) {
+		var log discordgo.GuildAuditLog
+		err := c.RequestJSON(ctx, "GET", urlStr, nil, &log)
+		for _, u := range log.Users {
+			it.users[u.ID] = u
+		}
+		for _, wh := range log.Webhooks {
+			it.webhooks[wh.ID] = wh
+		}
+		return log.AuditLogEntries, err
+	}
+	it.Iterator = newIterator(ctx, 100, pageSnowflakesWith(get, urlStr, q.values(), param, start, auditLogEntryID))
+	return it.Limit(q.Limit)
+}
+
+// Iterator over a guild's audit log; see Iterator. Also collects the users and webhooks that the
+// entries fetched so far reference, eg. by their UserID or TargetID.
+type AuditLogIterator struct {
+	*Iterator[*discordgo.AuditLogEntry]
+
+	users    map[string]*discordgo.User
+	webhooks map[string]*discordgo.Webhook
+}
+
+// Stop after n entries. Returns the iterator, for chaining.
+func (it *AuditLogIterator) Limit(n int) *AuditLogIterator {
+	it.Iterator.Limit(n)
+	return it
+}
+
+// Stop at the first entry fn returns false for. Returns the iterator, for chaining.
+func (it *AuditLogIterator) While(fn func(e *discordgo.AuditLogEntry) bool) *AuditLogIterator {
+	it.Iterator.While(fn)
+	return it
+}
+
+// Returns a user referenced by a fetched entry, or nil if there isn't one with the given ID.
+func (it *AuditLogIterator) User(id string) *discordgo.User {
+	return it.users[id]
+}
+
+// Returns a webhook referenced by a fetched entry, or nil if there isn't one with the given ID.
+func (it *AuditLogIterator) Webhook(id string) *discordgo.Webhook {
+	return it.webhooks[id]
+}
+
+// Returns an audit log entry's ID.
+func auditLogEntryID(e *discordgo.AuditLogEntry) string {
+	return e.ID
+}
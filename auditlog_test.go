@@ -0,0 +1,112 @@
+package dgo2poc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientGuildAuditLog(t *testing.T) {
+	// 150 entries, with IDs 1-150, returned newest first.
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/guilds/1234/audit-logs", req.URL.Path)
+		assert.Equal(t, "5678", req.URL.Query().Get("user_id"))
+		assert.Equal(t, "22", req.URL.Query().Get("action_type"))
+		before := 151
+		if s := req.URL.Query().Get("before"); s != "" {
+			before, _ = strconv.Atoi(s)
+		}
+		limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+		var log discordgo.GuildAuditLog
+		for id := before - 1; id > 0 && len(log.AuditLogEntries) < limit; id-- {
+			log.AuditLogEntries = append(log.AuditLogEntries, &discordgo.AuditLogEntry{ID: strconv.Itoa(id)})
+		}
+		require.NoError(t, json.NewEncoder(rw).Encode(log))
+	}))
+	defer srv.Close()
+	cl := newTestClient(srv)
+
+	entries, err := cl.GuildAuditLog(context.Background(), "1234",
+		AuditLogByUser("5678"),
+		AuditLogByAction(discordgo.AuditLogActionMemberBanAdd),
+	).All()
+	require.NoError(t, err)
+	require.Len(t, entries, 150)
+	assert.Equal(t, "150", entries[0].ID)
+	assert.Equal(t, "1", entries[149].ID)
+
+	t.Run("Before", func(t *testing.T) {
+		entries, err := cl.GuildAuditLog(context.Background(), "1234",
+			AuditLogByUser("5678"),
+			AuditLogByAction(discordgo.AuditLogActionMemberBanAdd),
+			AuditLogBefore("100"),
+			AuditLogLimit(10),
+		).All()
+		require.NoError(t, err)
+		require.Len(t, entries, 10)
+		assert.Equal(t, "99", entries[0].ID)
+		assert.Equal(t, "90", entries[9].ID)
+	})
+}
+
+func TestClientGuildAuditLogReferences(t *testing.T) {
+	srv, cl, _ := newTestServer(t, `{
+		"audit_log_entries":[{"id":"11","user_id":"5678","target_id":"9999"}],
+		"users":[{"id":"5678","username":"mod"}],
+		"webhooks":[{"id":"9999","name":"alerts"}]
+	}`)
+	defer srv.Close()
+
+	it := cl.GuildAuditLog(context.Background(), "1234").Limit(1)
+	require.True(t, it.Next())
+	require.NotNil(t, it.User(it.Value().UserID))
+	assert.Equal(t, "mod", it.User(it.Value().UserID).Username)
+	require.NotNil(t, it.Webhook(it.Value().TargetID))
+	assert.Equal(t, "alerts", it.Webhook(it.Value().TargetID).Name)
+	assert.Nil(t, it.User("1111"))
+	assert.False(t, it.Next())
+}
+
+func TestClientGuildAuditLogAfter(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"audit_log_entries":[{"id":"12","reason":"spam"},{"id":"11"}]}`)
+	defer srv.Close()
+
+	entries, err := cl.GuildAuditLog(context.Background(), "1234", AuditLogAfter("10")).All()
+	require.NoError(t, err)
+	assert.Equal(t, "after=10&limit=100", rec.Last().Query)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "11", entries[0].ID)
+	assert.Equal(t, "12", entries[1].ID)
+	assert.Equal(t, "spam", entries[1].Reason)
+}
+
+func TestClientWithAuditLogReason(t *testing.T) {
+	srv, cl, rec := newTestServer(t, "")
+	defer srv.Close()
+
+	ctx := WithReqOptions(context.Background(), WithAuditLogReason("unused"))
+	require.NoError(t, cl.GuildRoleDelete(ctx, "1234", "5678"))
+	assert.Equal(t, "unused", rec.Last().Header.Get("X-Audit-Log-Reason"))
+}
+
+func TestDispatchGuildAuditLogEntryCreate(t *testing.T) {
+	var pre, main wsHandlers
+	var got *GuildAuditLogEntryCreate
+	pre.Add(OnGuildAuditLogEntryCreate(func(ctx context.Context, ev *GuildAuditLogEntryCreate) {
+		got = ev
+	}))
+	data := []byte(`{"guild_id":"1234","id":"5678","user_id":"9999","action_type":22,"reason":"spam"}`)
+	require.NoError(t, dispatch(context.Background(), "GUILD_AUDIT_LOG_ENTRY_CREATE", data, &pre, &main))
+	require.NotNil(t, got)
+	assert.Equal(t, "1234", got.GuildID)
+	assert.Equal(t, "5678", got.ID)
+	assert.Equal(t, discordgo.AuditLogActionMemberBanAdd, *got.ActionType)
+	assert.Equal(t, "spam", got.Reason)
+}
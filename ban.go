@@ -0,0 +1,75 @@
+package dgo2poc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Max age of messages deleted along with a ban; see BanDeleteMessages().
+const BanDeleteMessagesMaxAge = 7 * 24 * time.Hour
+
+// A ban to create; see Client.GuildBanCreate().
+type BanCreate struct {
+	// Delete messages sent by the user in the last this many seconds, up to 7 days.
+	DeleteMessageSeconds int `json:"delete_message_seconds,omitempty"`
+
+	// Reason shown in the guild's audit log.
+	Reason string `json:"-"`
+}
+
+// Options for Client.GuildBanCreate().
+type BanOpt func(ban *BanCreate)
+
+// Delete messages the user sent within the given duration, rounded down to the second. Durations
+// longer than BanDeleteMessagesMaxAge are capped to it.
+func BanDeleteMessages(d time.Duration) BanOpt {
+	return BanOpt(func(ban *BanCreate) {
+		if d > BanDeleteMessagesMaxAge {
+			d = BanDeleteMessagesMaxAge
+		}
+		ban.DeleteMessageSeconds = int(d / time.Second)
+	})
+}
+
+// Set the reason shown in the guild's audit log.
+func BanWithReason(reason string) BanOpt {
+	return BanOpt(func(ban *BanCreate) {
+		ban.Reason = reason
+	})
+}
+
+func (c *client) GuildBan(ctx context.Context, gid, uid string) (*discordgo.GuildBan, error) {
+	var ban discordgo.GuildBan
+	return &ban, c.RequestJSON(ctx, "GET", c.BaseURL+EndpointGuildBan(gid, uid), nil, &ban)
+}
+
+func (c *client) GuildBans(ctx context.Context, gid string) *Iterator[*discordgo.GuildBan] {
+	urlStr := c.BaseURL + EndpointGuildBans(gid)
+	return newIterator(ctx, 1000, pageSnowflakes(c, urlStr, nil, "after", "", banUserID))
+}
+
+func (c *client) GuildBanCreate(ctx context.Context, gid, uid string, opts ...BanOpt) error {
+	var ban BanCreate
+	for _, opt := range opts {
+		opt(&ban)
+	}
+	data, err := json.Marshal(ban)
+	if err != nil {
+		return err
+	}
+	_, err = c.Request(ctx, "PUT", c.BaseURL+EndpointGuildBan(gid, uid), data, WithAuditLogReason(ban.Reason))
+	return err
+}
+
+func (c *client) GuildBanDelete(ctx context.Context, gid, uid, reason string) error {
+	_, err := c.Request(ctx, "DELETE", c.BaseURL+EndpointGuildBan(gid, uid), nil, WithAuditLogReason(reason))
+	return err
+}
+
+// Returns a banned user's ID.
+func banUserID(ban *discordgo.GuildBan) string {
+	return ban.User.ID
+}
@@ -0,0 +1,54 @@
+package dgo2poc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientGuildBan(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"reason":"spam","user":{"id":"5678"}}`)
+	defer srv.Close()
+
+	ban, err := cl.GuildBan(context.Background(), "1234", "5678")
+	require.NoError(t, err)
+	assert.Equal(t, "GET", rec.Last().Method)
+	assert.Equal(t, "/guilds/1234/bans/5678", rec.Last().Path)
+	assert.Equal(t, "spam", ban.Reason)
+}
+
+func TestClientGuildBans(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `[{"user":{"id":"2"}},{"user":{"id":"1"}}]`)
+	defer srv.Close()
+
+	bans, err := cl.GuildBans(context.Background(), "1234").All()
+	require.NoError(t, err)
+	assert.Equal(t, "/guilds/1234/bans", rec.Last().Path)
+	assert.Equal(t, "limit=1000", rec.Last().Query)
+	require.Len(t, bans, 2)
+	assert.Equal(t, "1", bans[0].User.ID)
+	assert.Equal(t, "2", bans[1].User.ID)
+}
+
+func TestClientGuildBanCreate(t *testing.T) {
+	srv, cl, rec := newTestServer(t, "")
+	defer srv.Close()
+	ctx := context.Background()
+
+	require.NoError(t, cl.GuildBanCreate(ctx, "1234", "5678", BanDeleteMessages(time.Hour), BanWithReason("spam bot")))
+	assert.Equal(t, "PUT", rec.Last().Method)
+	assert.Equal(t, "/guilds/1234/bans/5678", rec.Last().Path)
+	assert.Equal(t, "spam%20bot", rec.Last().Header.Get("X-Audit-Log-Reason"))
+	assert.JSONEq(t, `{"delete_message_seconds":3600}`, rec.Last().Body)
+
+	require.NoError(t, cl.GuildBanCreate(ctx, "1234", "5678", BanDeleteMessages(30*24*time.Hour)))
+	assert.JSONEq(t, `{"delete_message_seconds":604800}`, rec.Last().Body)
+
+	require.NoError(t, cl.GuildBanDelete(ctx, "1234", "5678", "appealed"))
+	assert.Equal(t, "DELETE", rec.Last().Method)
+	assert.Equal(t, "/guilds/1234/bans/5678", rec.Last().Path)
+	assert.Equal(t, "appealed", rec.Last().Header.Get("X-Audit-Log-Reason"))
+}
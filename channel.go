@@ -0,0 +1,303 @@
+package dgo2poc
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Parameters for creating or editing a channel; see Client.GuildChannelCreate() and Client.ChannelEdit().
+// Nil fields are left unset. Not all fields apply to all channel types.
+type ChannelParams struct {
+	Name     string                 `json:"name,omitempty"`
+	Type     *discordgo.ChannelType `json:"type,omitempty"`
+	Position *int                   `json:"position,omitempty"`
+
+	// Category the channel is in. Set to "" to move it out of its category.
+	ParentID *string `json:"-"`
+
+	PermissionOverwrites *[]*discordgo.PermissionOverwrite `json:"permission_overwrites,omitempty"`
+
+	// Text, announcement and forum channels.
+	Topic                      *string `json:"topic,omitempty"`
+	NSFW                       *bool   `json:"nsfw,omitempty"`
+	RateLimitPerUser           *int    `json:"rate_limit_per_user,omitempty"`
+	DefaultAutoArchiveDuration *int    `json:"default_auto_archive_duration,omitempty"`
+
+	// Voice and stage channels.
+	Bitrate   *int    `json:"bitrate,omitempty"`
+	UserLimit *int    `json:"user_limit,omitempty"`
+	RTCRegion *string `json:"-"`
+
+	// Forum channels.
+	AvailableTags                 *[]discordgo.ForumTag           `json:"available_tags,omitempty"`
+	DefaultReactionEmoji          *discordgo.ForumDefaultReaction `json:"default_reaction_emoji,omitempty"`
+	DefaultSortOrder              *discordgo.ForumSortOrderType   `json:"default_sort_order,omitempty"`
+	DefaultForumLayout            *discordgo.ForumLayout          `json:"default_forum_layout,omitempty"`
+	DefaultThreadRateLimitPerUser *int                            `json:"default_thread_rate_limit_per_user,omitempty"`
+
+	// Reason shown in the guild's audit log.
+	Reason string `json:"-"`
+}
+
+func (p ChannelParams) MarshalJSON() ([]byte, error) {
+	type channelParams ChannelParams
+	return json.Marshal(struct {
+		channelParams
+		ParentID  json.RawMessage `json:"parent_id,omitempty"`
+		RTCRegion json.RawMessage `json:"rtc_region,omitempty"`
+	}{channelParams(p), nullString(p.ParentID), nullString(p.RTCRegion)})
+}
+
+// Options for Client.GuildChannelCreate() and Client.ChannelEdit().
+type ChannelOpt func(params *ChannelParams)
+
+// Rename a channel.
+func ChannelWithName(name string) ChannelOpt {
+	return ChannelOpt(func(params *ChannelParams) {
+		params.Name = name
+	})
+}
+
+// Change a channel's type. Only conversion between text and announcement channels is supported.
+func ChannelWithType(typ discordgo.ChannelType) ChannelOpt {
+	return ChannelOpt(func(params *ChannelParams) {
+		params.Type = &typ
+	})
+}
+
+// Set a channel's position in the channel list.
+func ChannelWithPosition(pos int) ChannelOpt {
+	return ChannelOpt(func(params *ChannelParams) {
+		params.Position = &pos
+	})
+}
+
+// Put a channel in a category. Use "" to move it out of its category.
+func ChannelWithParent(category string) ChannelOpt {
+	return ChannelOpt(func(params *ChannelParams) {
+		params.ParentID = &category
+	})
+}
+
+// Replace a channel's permission overwrites. May be given multiple times, to add more.
+func ChannelWithPermissionOverwrites(overwrites ...*discordgo.PermissionOverwrite) ChannelOpt {
+	return ChannelOpt(func(params *ChannelParams) {
+		var all []*discordgo.PermissionOverwrite
+		if params.PermissionOverwrites != nil {
+			all = *params.PermissionOverwrites
+		}
+		all = append(all, overwrites...)
+		params.PermissionOverwrites = &all
+	})
+}
+
+// Set a text, announcement or forum channel's topic.
+func ChannelWithTopic(topic string) ChannelOpt {
+	return ChannelOpt(func(params *ChannelParams) {
+		params.Topic = &topic
+	})
+}
+
+// Mark a channel as age-restricted, or not.
+func ChannelWithNSFW(nsfw bool) ChannelOpt {
+	return ChannelOpt(func(params *ChannelParams) {
+		params.NSFW = &nsfw
+	})
+}
+
+// Set a channel's slowmode, rounded down to the second, up to 6 hours. Use 0 to disable it.
+func ChannelWithSlowmode(d time.Duration) ChannelOpt {
+	return ChannelOpt(func(params *ChannelParams) {
+		secs := int(d / time.Second)
+		params.RateLimitPerUser = &secs
+	})
+}
+
+// Set how long threads in a channel stay active without messages before they're archived.
+// Discord only accepts 1 hour, 24 hours, 3 days or 1 week.
+func ChannelWithDefaultAutoArchive(d time.Duration) ChannelOpt {
+	return ChannelOpt(func(params *ChannelParams) {
+		mins := int(d / time.Minute)
+		params.DefaultAutoArchiveDuration = &mins
+	})
+}
+
+// Set a voice or stage channel's bitrate, in bits per second.
+func ChannelWithBitrate(bitrate int) ChannelOpt {
+	return ChannelOpt(func(params *ChannelParams) {
+		params.Bitrate = &bitrate
+	})
+}
+
+// Set how many users can join a voice or stage channel. Use 0 for no limit.
+func ChannelWithUserLimit(n int) ChannelOpt {
+	return ChannelOpt(func(params *ChannelParams) {
+		params.UserLimit = &n
+	})
+}
+
+// Set a voice or stage channel's voice region. Use "" to pick one automatically.
+func ChannelWithRTCRegion(region string) ChannelOpt {
+	return ChannelOpt(func(params *ChannelParams) {
+		params.RTCRegion = &region
+	})
+}
+
+// Replace the tags available in a forum channel. Tags without IDs are created.
+func ChannelWithForumTags(tags ...discordgo.ForumTag) ChannelOpt {
+	return ChannelOpt(func(params *ChannelParams) {
+		all := append([]discordgo.ForumTag{}, tags...)
+		params.AvailableTags = &all
+	})
+}
+
+// Set the emoji added to new posts in a forum channel, as unicode, or custom emoji as "name:id" or "<:name:id>".
+func ChannelWithDefaultReaction(emoji string) ChannelOpt {
+	return ChannelOpt(func(params *ChannelParams) {
+		var reaction discordgo.ForumDefaultReaction
+		if name := EmojiAPIName(emoji); strings.Contains(name, ":") {
+			reaction.EmojiID = name[strings.LastIndex(name, ":")+1:]
+		} else {
+			reaction.EmojiName = name
+		}
+		params.DefaultReactionEmoji = &reaction
+	})
+}
+
+// Set how posts in a forum channel are sorted by default.
+func ChannelWithDefaultSortOrder(order discordgo.ForumSortOrderType) ChannelOpt {
+	return ChannelOpt(func(params *ChannelParams) {
+		params.DefaultSortOrder = &order
+	})
+}
+
+// Set how posts in a forum channel are displayed by default.
+func ChannelWithDefaultForumLayout(layout discordgo.ForumLayout) ChannelOpt {
+	return ChannelOpt(func(params *ChannelParams) {
+		params.DefaultForumLayout = &layout
+	})
+}
+
+// Set the initial slowmode of new threads in a channel, rounded down to the second.
+func ChannelWithDefaultThreadSlowmode(d time.Duration) ChannelOpt {
+	return ChannelOpt(func(params *ChannelParams) {
+		secs := int(d / time.Second)
+		params.DefaultThreadRateLimitPerUser = &secs
+	})
+}
+
+// Set the reason shown in the guild's audit log.
+func ChannelWithReason(reason string) ChannelOpt {
+	return ChannelOpt(func(params *ChannelParams) {
+		params.Reason = reason
+	})
+}
+
+// An edit to a permission overwrite; see Client.ChannelPermissionEdit().
+type PermissionOverwriteEdit struct {
+	Type  discordgo.PermissionOverwriteType `json:"type"`
+	Allow int64                             `json:"allow,string"`
+	Deny  int64                             `json:"deny,string"`
+
+	// Reason shown in the guild's audit log.
+	Reason string `json:"-"`
+}
+
+// Options for Client.ChannelPermissionEdit().
+type OverwriteOpt func(edit *PermissionOverwriteEdit)
+
+// Explicitly allow the given permissions.
+func OverwriteAllow(perms int64) OverwriteOpt {
+	return OverwriteOpt(func(edit *PermissionOverwriteEdit) {
+		edit.Allow |= perms
+	})
+}
+
+// Explicitly deny the given permissions.
+func OverwriteDeny(perms int64) OverwriteOpt {
+	return OverwriteOpt(func(edit *PermissionOverwriteEdit) {
+		edit.Deny |= perms
+	})
+}
+
+// Set the reason shown in the guild's audit log.
+func OverwriteWithReason(reason string) OverwriteOpt {
+	return OverwriteOpt(func(edit *PermissionOverwriteEdit) {
+		edit.Reason = reason
+	})
+}
+
+func (c *client) Channel(ctx context.Context, cid string) (*discordgo.Channel, error) {
+	var channel discordgo.Channel
+	return &channel, c.RequestJSON(ctx, "GET", c.BaseURL+EndpointChannel(cid), nil, &channel)
+}
+
+func (c *client) GuildChannelCreate(ctx context.Context, gid, name string, typ discordgo.ChannelType, opts ...ChannelOpt) (*discordgo.Channel, error) {
+	params := ChannelParams{Name: name, Type: &typ}
+	return c.requestChannel(ctx, "POST", c.BaseURL+EndpointGuildChannels(gid), params, opts)
+}
+
+func (c *client) ChannelEdit(ctx context.Context, cid string, opts ...ChannelOpt) (*discordgo.Channel, error) {
+	return c.requestChannel(ctx, "PATCH", c.BaseURL+EndpointChannel(cid), ChannelParams{}, opts)
+}
+
+func (c *client) requestChannel(ctx context.Context, method, urlStr string, params ChannelParams, opts []ChannelOpt) (*discordgo.Channel, error) {
+	for _, opt := range opts {
+		opt(&params)
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	var channel discordgo.Channel
+	return &channel, c.RequestJSON(ctx, method, urlStr, data, &channel, WithAuditLogReason(params.Reason))
+}
+
+func (c *client) ChannelDelete(ctx context.Context, cid, reason string) error {
+	_, err := c.Request(ctx, "DELETE", c.BaseURL+EndpointChannel(cid), nil, WithAuditLogReason(reason))
+	return err
+}
+
+func (c *client) ChannelPermissionEdit(ctx context.Context, cid, id string, typ discordgo.PermissionOverwriteType, opts ...OverwriteOpt) error {
+	edit := PermissionOverwriteEdit{Type: typ}
+	for _, opt := range opts {
+		opt(&edit)
+	}
+	data, err := json.Marshal(edit)
+	if err != nil {
+		return err
+	}
+	_, err = c.Request(ctx, "PUT", c.BaseURL+EndpointChannelPermission(cid, id), data, WithAuditLogReason(edit.Reason))
+	return err
+}
+
+func (c *client) ChannelPermissionDelete(ctx context.Context, cid, id, reason string) error {
+	_, err := c.Request(ctx, "DELETE", c.BaseURL+EndpointChannelPermission(cid, id), nil, WithAuditLogReason(reason))
+	return err
+}
+
+func (c *client) ChannelFollow(ctx context.Context, cid, target, reason string) (*discordgo.ChannelFollow, error) {
+	data, err := json.Marshal(map[string]string{"webhook_channel_id": target})
+	if err != nil {
+		return nil, err
+	}
+	var follow discordgo.ChannelFollow
+	return &follow, c.RequestJSON(ctx, "POST", c.BaseURL+EndpointChannelFollowers(cid), data, &follow,
+		WithAuditLogReason(reason))
+}
+
+// Returns a nullable string as JSON: nothing if it's nil, null if it's "".
+func nullString(s *string) json.RawMessage {
+	switch {
+	case s == nil:
+		return nil
+	case *s == "":
+		return json.RawMessage(`null`)
+	}
+	data, _ := json.Marshal(*s)
+	return data
+}
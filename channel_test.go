@@ -0,0 +1,139 @@
+package dgo2poc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientChannel(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"id":"1234","name":"general"}`)
+	defer srv.Close()
+
+	channel, err := cl.Channel(context.Background(), "1234")
+	require.NoError(t, err)
+	assert.Equal(t, "GET", rec.Last().Method)
+	assert.Equal(t, "/channels/1234", rec.Last().Path)
+	assert.Equal(t, "general", channel.Name)
+}
+
+func TestClientGuildChannelCreate(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"id":"5678"}`)
+	defer srv.Close()
+	ctx := context.Background()
+
+	t.Run("Text", func(t *testing.T) {
+		_, err := cl.GuildChannelCreate(ctx, "1234", "general", discordgo.ChannelTypeGuildText,
+			ChannelWithTopic("hi"),
+			ChannelWithParent("9999"),
+			ChannelWithSlowmode(30*time.Second),
+			ChannelWithPermissionOverwrites(&discordgo.PermissionOverwrite{
+				ID:   "1234",
+				Type: discordgo.PermissionOverwriteTypeRole,
+				Deny: discordgo.PermissionSendMessages,
+			}),
+			ChannelWithReason("setup"),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "POST", rec.Last().Method)
+		assert.Equal(t, "/guilds/1234/channels", rec.Last().Path)
+		assert.Equal(t, "setup", rec.Last().Header.Get("X-Audit-Log-Reason"))
+		assert.JSONEq(t, `{
+			"name": "general",
+			"type": 0,
+			"topic": "hi",
+			"parent_id": "9999",
+			"rate_limit_per_user": 30,
+			"permission_overwrites": [{"id":"1234","type":0,"allow":"0","deny":"2048"}]
+		}`, rec.Last().Body)
+	})
+
+	t.Run("Voice", func(t *testing.T) {
+		_, err := cl.GuildChannelCreate(ctx, "1234", "voice", discordgo.ChannelTypeGuildVoice,
+			ChannelWithBitrate(64000),
+			ChannelWithUserLimit(10),
+		)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name":"voice","type":2,"bitrate":64000,"user_limit":10}`, rec.Last().Body)
+	})
+
+	t.Run("Forum", func(t *testing.T) {
+		_, err := cl.GuildChannelCreate(ctx, "1234", "forum", discordgo.ChannelTypeGuildForum,
+			ChannelWithForumTags(discordgo.ForumTag{Name: "bug"}),
+			ChannelWithDefaultReaction("<:blobcat:1111>"),
+			ChannelWithDefaultSortOrder(discordgo.ForumSortOrderCreationDate),
+			ChannelWithDefaultAutoArchive(24*time.Hour),
+		)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"name": "forum",
+			"type": 15,
+			"available_tags": [{"name":"bug","moderated":false}],
+			"default_reaction_emoji": {"emoji_id":"1111"},
+			"default_sort_order": 1,
+			"default_auto_archive_duration": 1440
+		}`, rec.Last().Body)
+	})
+}
+
+func TestClientChannelEdit(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"id":"5678"}`)
+	defer srv.Close()
+	ctx := context.Background()
+
+	_, err := cl.ChannelEdit(ctx, "5678", ChannelWithName("renamed"), ChannelWithPosition(0))
+	require.NoError(t, err)
+	assert.Equal(t, "PATCH", rec.Last().Method)
+	assert.Equal(t, "/channels/5678", rec.Last().Path)
+	assert.JSONEq(t, `{"name":"renamed","position":0}`, rec.Last().Body)
+
+	_, err = cl.ChannelEdit(ctx, "5678", ChannelWithParent(""), ChannelWithRTCRegion(""))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"parent_id":null,"rtc_region":null}`, rec.Last().Body)
+}
+
+func TestClientChannelDelete(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"id":"5678"}`)
+	defer srv.Close()
+
+	require.NoError(t, cl.ChannelDelete(context.Background(), "5678", "cleanup"))
+	assert.Equal(t, "DELETE", rec.Last().Method)
+	assert.Equal(t, "/channels/5678", rec.Last().Path)
+	assert.Equal(t, "cleanup", rec.Last().Header.Get("X-Audit-Log-Reason"))
+}
+
+func TestClientChannelPermissions(t *testing.T) {
+	srv, cl, rec := newTestServer(t, "")
+	defer srv.Close()
+	ctx := context.Background()
+
+	require.NoError(t, cl.ChannelPermissionEdit(ctx, "5678", "1234", discordgo.PermissionOverwriteTypeMember,
+		OverwriteAllow(discordgo.PermissionViewChannel),
+		OverwriteDeny(discordgo.PermissionSendMessages),
+		OverwriteWithReason("muted"),
+	))
+	assert.Equal(t, "PUT", rec.Last().Method)
+	assert.Equal(t, "/channels/5678/permissions/1234", rec.Last().Path)
+	assert.Equal(t, "muted", rec.Last().Header.Get("X-Audit-Log-Reason"))
+	assert.JSONEq(t, `{"type":1,"allow":"1024","deny":"2048"}`, rec.Last().Body)
+
+	require.NoError(t, cl.ChannelPermissionDelete(ctx, "5678", "1234", ""))
+	assert.Equal(t, "DELETE", rec.Last().Method)
+	assert.Equal(t, "/channels/5678/permissions/1234", rec.Last().Path)
+}
+
+func TestClientChannelFollow(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"channel_id":"5678","webhook_id":"9999"}`)
+	defer srv.Close()
+
+	follow, err := cl.ChannelFollow(context.Background(), "5678", "1234", "")
+	require.NoError(t, err)
+	assert.Equal(t, "POST", rec.Last().Method)
+	assert.Equal(t, "/channels/5678/followers", rec.Last().Path)
+	assert.JSONEq(t, `{"webhook_channel_id":"1234"}`, rec.Last().Body)
+	assert.Equal(t, "9999", follow.WebhookID)
+}
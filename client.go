@@ -5,16 +5,17 @@ import (
 	"context"
 	"encoding/json"
 	"io/ioutil"
+	"log"
 	"net/http"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
-	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
 )
 
 // Client for the Discord REST API.
 type Client interface {
-	// Make an arbitrary request.
+	// Make an arbitrary request. Error statuses are returned as an *HTTPError.
 	Request(ctx context.Context, method, urlStr string, body []byte, opts ...ReqOption) ([]byte, error)
 
 	// Make an arbitrary request, which returns a JSON object.
@@ -27,6 +28,243 @@ type Client interface {
 	// Sends a message to the given channel.
 	ChannelMessageCreate(ctx context.Context, channel, content string, opts ...SendOpt) (*discordgo.Message, error)
 
+	// Sends content longer than MessageMaxLength as multiple messages; see SplitMessage().
+	// Files, embeds and components are only attached to the last message, replies only
+	// apply to the first. Returns all messages that were created, even if an error occurs.
+	ChannelMessageCreateSplit(ctx context.Context, channel, content string, opts ...SendOpt) ([]*discordgo.Message, error)
+
+	// Returns a single message from a channel.
+	ChannelMessage(ctx context.Context, channel, id string) (*discordgo.Message, error)
+
+	// Returns an iterator over messages in a channel, newest first, or oldest first if MessagesAfter()
+	// is used. Use MessagesBefore(), MessagesAfter() or MessagesAround() to pick where to start.
+	ChannelMessages(ctx context.Context, channel string, opts ...MessagesOpt) *Iterator[*discordgo.Message]
+
+	// Edits a message. Only fields set by the given options are changed.
+	ChannelMessageEdit(ctx context.Context, channel, id string, opts ...EditOpt) (*discordgo.Message, error)
+
+	// Deletes a message.
+	ChannelMessageDelete(ctx context.Context, channel, id string) error
+
+	// Deletes multiple messages from a channel. Messages younger than 14 days are deleted in bulk,
+	// in batches of up to 100; older ones can't be, and are deleted one at a time instead.
+	// A result is returned for each message; if any of them failed, the first error is also returned.
+	ChannelMessagesBulkDelete(ctx context.Context, channel string, ids []string) ([]DeleteResult, error)
+
+	// Deletes up to limit messages from a channel, newest first, paging through its history.
+	// If match is not nil, only messages it returns true for are deleted. See ChannelMessagesBulkDelete().
+	ChannelPurge(ctx context.Context, channel string, limit int, match func(msg *discordgo.Message) bool) ([]DeleteResult, error)
+
+	// Pins a message in a channel.
+	ChannelMessagePin(ctx context.Context, channel, id string) error
+
+	// Unpins a message in a channel.
+	ChannelMessageUnpin(ctx context.Context, channel, id string) error
+
+	// Returns all pinned messages in a channel.
+	ChannelMessagesPinned(ctx context.Context, channel string) ([]*discordgo.Message, error)
+
+	// Reacts to a message. Emoji may be unicode, or custom emoji as "name:id" or "<:name:id>".
+	MessageReactionAdd(ctx context.Context, channel, id, emoji string) error
+
+	// Removes a user's reaction from a message. Use the user ID "@me" to remove your own.
+	MessageReactionRemove(ctx context.Context, channel, id, emoji, user string) error
+
+	// Removes all reactions from a message.
+	MessageReactionsRemoveAll(ctx context.Context, channel, id string) error
+
+	// Removes all reactions with a given emoji from a message.
+	MessageReactionsRemoveEmoji(ctx context.Context, channel, id, emoji string) error
+
+	// Returns an iterator over users who reacted to a message with an emoji.
+	MessageReactions(ctx context.Context, channel, id, emoji string) *ReactionIterator
+
+	// Returns a guild, including approximate member and presence counts.
+	Guild(ctx context.Context, id string) (*discordgo.Guild, error)
+
+	// Returns a guild's public preview. Works for discoverable guilds the user isn't a member of.
+	GuildPreview(ctx context.Context, id string) (*discordgo.GuildPreview, error)
+
+	// Edits a guild's settings. Only fields set by the given options are changed.
+	GuildEdit(ctx context.Context, id string, opts ...GuildOpt) (*discordgo.Guild, error)
+
+	// Returns an iterator over guilds the authenticating user is a member of.
+	UserGuilds(ctx context.Context) *Iterator[*discordgo.UserGuild]
+
+	// Returns a channel.
+	Channel(ctx context.Context, id string) (*discordgo.Channel, error)
+
+	// Creates a channel of any type in a guild, eg. discordgo.ChannelTypeGuildText or ChannelTypeGuildForum.
+	GuildChannelCreate(ctx context.Context, guild, name string, typ discordgo.ChannelType, opts ...ChannelOpt) (*discordgo.Channel, error)
+
+	// Edits a channel. Only fields set by the given options are changed.
+	ChannelEdit(ctx context.Context, id string, opts ...ChannelOpt) (*discordgo.Channel, error)
+
+	// Deletes a channel, or closes a DM. The reason is shown in the audit log, and may be empty.
+	ChannelDelete(ctx context.Context, id, reason string) error
+
+	// Creates or replaces a channel's permission overwrite for a role or member.
+	ChannelPermissionEdit(ctx context.Context, channel, id string, typ discordgo.PermissionOverwriteType, opts ...OverwriteOpt) error
+
+	// Deletes a channel's permission overwrite for a role or member.
+	// The reason is shown in the audit log, and may be empty.
+	ChannelPermissionDelete(ctx context.Context, channel, id, reason string) error
+
+	// Follows an announcement channel, crossposting its messages to the target channel.
+	// The reason is shown in the target guild's audit log, and may be empty.
+	ChannelFollow(ctx context.Context, channel, target, reason string) (*discordgo.ChannelFollow, error)
+
+	// Returns all channels in a guild, excluding threads.
+	GuildChannels(ctx context.Context, guild string) ([]*discordgo.Channel, error)
+
+	// Moves channels in a guild. Only the given channels are moved.
+	GuildChannelsReorder(ctx context.Context, guild string, positions []ChannelPosition) error
+
+	// Returns all roles in a guild.
+	GuildRoles(ctx context.Context, guild string) ([]*discordgo.Role, error)
+
+	// Creates a role in a guild. With no options, creates a role named "new role" with no permissions.
+	GuildRoleCreate(ctx context.Context, guild string, opts ...RoleOpt) (*discordgo.Role, error)
+
+	// Edits a role. Only fields set by the given options are changed.
+	GuildRoleEdit(ctx context.Context, guild, id string, opts ...RoleOpt) (*discordgo.Role, error)
+
+	// Moves roles in a guild's hierarchy. Returns all of the guild's roles, after the move.
+	GuildRolesReorder(ctx context.Context, guild string, positions []RolePosition) ([]*discordgo.Role, error)
+
+	// Deletes a role.
+	GuildRoleDelete(ctx context.Context, guild, id string) error
+
+	// Returns a guild member.
+	GuildMember(ctx context.Context, guild, id string) (*discordgo.Member, error)
+
+	// Returns an iterator over members of a guild, ordered by user ID.
+	// Requires the GUILD_MEMBERS privileged intent.
+	GuildMembers(ctx context.Context, guild string) *Iterator[*discordgo.Member]
+
+	// Returns up to limit (max 1000) members whose username or nickname starts with query.
+	// If limit is 0, Discord's default of 1 is used.
+	GuildMemberSearch(ctx context.Context, guild, query string, limit int) ([]*discordgo.Member, error)
+
+	// Edits a guild member, eg. to change their nickname or time them out.
+	// Only fields set by the given options are changed.
+	GuildMemberEdit(ctx context.Context, guild, id string, opts ...MemberOpt) (*discordgo.Member, error)
+
+	// Adds a role to a guild member. The reason is shown in the audit log, and may be empty.
+	GuildMemberRoleAdd(ctx context.Context, guild, id, role, reason string) error
+
+	// Removes a role from a guild member. The reason is shown in the audit log, and may be empty.
+	GuildMemberRoleRemove(ctx context.Context, guild, id, role, reason string) error
+
+	// Kicks a member from a guild. The reason is shown in the audit log, and may be empty.
+	GuildMemberKick(ctx context.Context, guild, id, reason string) error
+
+	// Returns the ban for a user, or an error matching ErrNotFound if they aren't banned.
+	GuildBan(ctx context.Context, guild, id string) (*discordgo.GuildBan, error)
+
+	// Returns an iterator over a guild's bans, ordered by user ID.
+	GuildBans(ctx context.Context, guild string) *Iterator[*discordgo.GuildBan]
+
+	// Bans a user from a guild, optionally deleting their recent messages.
+	GuildBanCreate(ctx context.Context, guild, id string, opts ...BanOpt) error
+
+	// Unbans a user from a guild. The reason is shown in the audit log, and may be empty.
+	GuildBanDelete(ctx context.Context, guild, id, reason string) error
+
+	// Returns an iterator over a guild's audit log, newest first, or oldest first if AuditLogAfter() is used.
+	GuildAuditLog(ctx context.Context, guild string, opts ...AuditLogOpt) *AuditLogIterator
+
+	// Returns how many members a prune with the given options would kick.
+	GuildPruneCount(ctx context.Context, guild string, opts ...PruneOpt) (int, error)
+
+	// Kicks inactive members from a guild. Returns how many were kicked, unless PruneWithoutCount() is used.
+	GuildPrune(ctx context.Context, guild string, opts ...PruneOpt) (int, error)
+
+	// Returns a webhook.
+	Webhook(ctx context.Context, id string) (*discordgo.Webhook, error)
+
+	// Returns all webhooks in a channel.
+	ChannelWebhooks(ctx context.Context, channel string) ([]*discordgo.Webhook, error)
+
+	// Returns all webhooks in a guild.
+	GuildWebhooks(ctx context.Context, guild string) ([]*discordgo.Webhook, error)
+
+	// Creates a webhook in a channel. Use NewWebhookClient() with its ID and token to execute it.
+	WebhookCreate(ctx context.Context, channel, name string, opts ...WebhookOpt) (*discordgo.Webhook, error)
+
+	// Edits a webhook. Only fields set by the given options are changed.
+	WebhookEdit(ctx context.Context, id string, opts ...WebhookOpt) (*discordgo.Webhook, error)
+
+	// Deletes a webhook. The reason is shown in the audit log, and may be empty.
+	WebhookDelete(ctx context.Context, id, reason string) error
+
+	// Responds to an interaction with a message. Use SendWithFlags(discordgo.MessageFlagsEphemeral)
+	// to only show it to the user who triggered the interaction. Must be called within
+	// InteractionResponseTimeout, else an *InteractionExpiredError is returned.
+	InteractionRespond(ctx context.Context, i *discordgo.Interaction, content string, opts ...SendOpt) error
+
+	// Acknowledges an interaction, to respond later with InteractionResponseEdit(). For commands, shows
+	// a loading state; for components and modals, defers an update of the message they're attached to.
+	InteractionDefer(ctx context.Context, i *discordgo.Interaction, opts ...SendOpt) error
+
+	// Responds to a component interaction by editing the message it's attached to.
+	InteractionUpdate(ctx context.Context, i *discordgo.Interaction, opts ...EditOpt) error
+
+	// Responds to an interaction by showing the user a modal.
+	InteractionRespondModal(ctx context.Context, i *discordgo.Interaction, customID, title string, components ...discordgo.MessageComponent) error
+
+	// Responds to an autocomplete interaction with up to AutocompleteMaxChoices choices.
+	InteractionAutocomplete(ctx context.Context, i *discordgo.Interaction, choices ...*discordgo.ApplicationCommandOptionChoice) error
+
+	// Returns the initial response to an interaction. Like all follow-up methods, this returns an
+	// *InteractionExpiredError if the interaction's token is older than InteractionTokenLifetime.
+	InteractionResponse(ctx context.Context, i *discordgo.Interaction) (*discordgo.Message, error)
+
+	// Edits the initial response to an interaction, or sends a deferred one.
+	InteractionResponseEdit(ctx context.Context, i *discordgo.Interaction, opts ...EditOpt) (*discordgo.Message, error)
+
+	// Deletes the initial response to an interaction.
+	InteractionResponseDelete(ctx context.Context, i *discordgo.Interaction) error
+
+	// Sends a follow-up message for an interaction.
+	InteractionFollowup(ctx context.Context, i *discordgo.Interaction, content string, opts ...SendOpt) (*discordgo.Message, error)
+
+	// Edits a follow-up message.
+	InteractionFollowupEdit(ctx context.Context, i *discordgo.Interaction, id string, opts ...EditOpt) (*discordgo.Message, error)
+
+	// Deletes a follow-up message.
+	InteractionFollowupDelete(ctx context.Context, i *discordgo.Interaction, id string) error
+
+	// Returns an application's commands, including localisations. Pass a guild ID to return its
+	// guild-specific commands, or "" for global ones; the same goes for the other command methods.
+	ApplicationCommands(ctx context.Context, app, guild string) ([]*discordgo.ApplicationCommand, error)
+
+	// Registers a command. If one with the same name and type exists, it's replaced.
+	ApplicationCommandCreate(ctx context.Context, app, guild string, cmd *discordgo.ApplicationCommand) (*discordgo.ApplicationCommand, error)
+
+	// Edits a command. Only fields set in cmd are changed.
+	ApplicationCommandEdit(ctx context.Context, app, guild, id string, cmd *discordgo.ApplicationCommand) (*discordgo.ApplicationCommand, error)
+
+	// Deletes a command.
+	ApplicationCommandDelete(ctx context.Context, app, guild, id string) error
+
+	// Replaces all of an application's commands in a scope. Commands not given are deleted.
+	ApplicationCommandsOverwrite(ctx context.Context, app, guild string, cmds []*discordgo.ApplicationCommand) ([]*discordgo.ApplicationCommand, error)
+
+	// Returns a command's permissions in a guild.
+	ApplicationCommandPermissions(ctx context.Context, app, guild, id string) (*discordgo.GuildApplicationCommandPermissions, error)
+
+	// Replaces a command's permissions in a guild. This requires a Bearer token with the
+	// applications.commands.permissions.update scope; bot tokens can't do this.
+	ApplicationCommandPermissionsEdit(ctx context.Context, app, guild, id string, perms []*discordgo.ApplicationCommandPermissions) (*discordgo.GuildApplicationCommandPermissions, error)
+
+	// Registers, updates and deletes an application's commands to match cmds, and returns the changes
+	// made. Commands with a GuildID are registered in that guild; others globally. Only scopes that
+	// commands are given for are synced, unless others are requested with SyncGlobal() or SyncGuilds(),
+	// and only commands that differ from what's registered are changed. Use SyncDryRun() to only
+	// return the plan.
+	SyncCommands(ctx context.Context, app string, cmds []*discordgo.ApplicationCommand, opts ...SyncOpt) (*CommandPlan, error)
+
 	// Returns a gateway for a websocket connection.
 	// Depending on the type of token used, this will call either /gateway or /gateway/bot;
 	// the two are identical, except the latter will also provide a suggested shard count.
@@ -41,6 +279,8 @@ type client struct {
 	HTTPClient *http.Client
 	BaseURL    string
 	Opts       []ReqOption
+
+	RateLimiter RateLimiter
 }
 
 // Create a new client. Use UserToken() or BotToken() to wrap a token.
@@ -51,52 +291,117 @@ func NewClient(t *oauth2.Token, opts ...ReqOption) Client {
 			context.Background(),
 			oauth2.StaticTokenSource(t),
 		),
-		BaseURL: BaseURL,
-		Opts:    opts,
+		BaseURL:     BaseURL,
+		Opts:        opts,
+		RateLimiter: NewRateLimiter(),
 	}
 }
 
 func (c *client) Request(ctx context.Context, method, urlStr string, body []byte, opts ...ReqOption) ([]byte, error) {
-	// Create a request, set defaults.
 	req, err := http.NewRequest(method, urlStr, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, err
 	}
+	return c.do(ctx, req, opts...)
+}
+
+// Sends a request, applying options, rate limits and retries.
+// The body is taken from req.GetBody() for every attempt.
+func (c *client) do(ctx context.Context, req *http.Request, opts ...ReqOption) ([]byte, error) {
+	// Set defaults.
+	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", UserAgent)
 	req.Header.Set("Content-Type", "application/json")
 
 	// Apply options.
-	reqOpts := ReqOptions{Request: req}
+	reqOpts := ReqOptions{
+		Request:          req,
+		RateLimiter:      c.RateLimiter,
+		RateLimitRetries: DefaultRateLimitRetries,
+		Retry:            DefaultRetryPolicy,
+	}
 	for _, opt := range c.Opts {
 		opt(&reqOpts)
 	}
+	for _, opt := range getReqOptions(ctx) {
+		opt(&reqOpts)
+	}
 	for _, opt := range opts {
 		opt(&reqOpts)
 	}
 
-	// Send the request...
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
+	route := rateLimitRoute(req.Method, req.URL)
+	var failures, rateLimited int
+	for {
+		// Wait until the route's rate limit bucket lets us through.
+		if err := reqOpts.RateLimiter.Wait(ctx, route); err != nil {
+			return nil, err
+		}
 
-	// Always read and close the body, else connections can't be reused.
-	data, err := ioutil.ReadAll(resp.Body)
-	_ = resp.Body.Close()
-	if err != nil {
-		return nil, err
-	}
+		// Send the request...
+		attemptReq := req.Clone(ctx)
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		attemptReq.Body = body
+		resp, err := c.HTTPClient.Do(attemptReq)
+		if err != nil {
+			if ctx.Err() == nil && reqOpts.canRetry(failures) {
+				failures++
+				if err := sleepContext(ctx, reqOpts.Retry.Backoff(failures)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			return nil, err
+		}
+		if err := reqOpts.RateLimiter.Update(ctx, route, resp.Header); err != nil {
+			// The request itself went through; failing it here could make callers retry it.
+			log.Printf("client: couldn't update rate limiter: %s", err)
+		}
 
-	// Handle status codes.
-	if resp.StatusCode < 200 || resp.StatusCode > 399 {
-		var apiErr APIError
-		if err := json.Unmarshal(data, &apiErr); err != nil {
-			return data, errors.Errorf("%d: %s", resp.StatusCode, string(data))
+		// Always read and close the body, else connections can't be reused.
+		data, err := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, err
 		}
-		return data, errors.Errorf("%d: %s", resp.StatusCode, apiErr)
-	}
 
-	return data, nil
+		// Rate limited requests are retried once the limit has passed. The limiter should
+		// already hold them back, but may not have been told for how long, so wait here too.
+		if resp.StatusCode == http.StatusTooManyRequests {
+			rlErr := newRateLimitError(newHTTPError(resp, data))
+			if rateLimited < reqOpts.RateLimitRetries {
+				rateLimited++
+				delay := rlErr.RetryAfter
+				if delay <= 0 {
+					delay = fallbackRetryAfter
+				}
+				if err := sleepContext(ctx, delay); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			return data, rlErr
+		}
+
+		// Transient server errors are retried with a backoff.
+		if isTransientStatus(resp.StatusCode) && reqOpts.canRetry(failures) {
+			failures++
+			if err := sleepContext(ctx, reqOpts.Retry.Backoff(failures)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// Handle status codes.
+		if resp.StatusCode < 200 || resp.StatusCode > 399 {
+			return data, newHTTPError(resp, data)
+		}
+
+		return data, nil
+	}
 }
 
 func (c *client) RequestJSON(ctx context.Context, method, urlStr string, body []byte, out interface{}, opts ...ReqOption) error {
@@ -113,16 +418,217 @@ func (c *client) User(ctx context.Context, id string) (*discordgo.User, error) {
 }
 
 func (c *client) ChannelMessageCreate(ctx context.Context, cid, content string, opts ...SendOpt) (*discordgo.Message, error) {
-	send := discordgo.MessageSend{Content: content}
+	return c.channelMessageCreate(ctx, cid, newMessageSend(content, opts))
+}
+
+func (c *client) ChannelMessageCreateSplit(ctx context.Context, cid, content string, opts ...SendOpt) ([]*discordgo.Message, error) {
+	send := newMessageSend(content, opts)
+	chunks := SplitMessage(content, MessageMaxLength)
+	msgs := make([]*discordgo.Message, 0, len(chunks))
+	for i, chunk := range chunks {
+		part := send
+		part.Content = chunk
+		if i > 0 {
+			part.Reference = nil
+		}
+		if i < len(chunks)-1 {
+			part.Embeds = nil
+			part.Components = nil
+			part.Files = nil
+			part.Attachments = nil
+			part.Nonce = ""
+			part.EnforceNonce = false
+		}
+		msg, err := c.channelMessageCreate(ctx, cid, part)
+		if err != nil {
+			return msgs, err
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+func (c *client) channelMessageCreate(ctx context.Context, cid string, send MessageSend) (*discordgo.Message, error) {
+	// With a nonce, Discord deduplicates the message, so it's safe to retry.
+	if send.Nonce == "" {
+		send.Nonce = newNonce()
+		send.EnforceNonce = true
+	}
+	var reqOpts []ReqOption
+	if send.EnforceNonce {
+		reqOpts = append(reqOpts, WithIdempotent())
+	}
+
+	var msg discordgo.Message
+	send.Attachments = append(send.Attachments, fileAttachments(send.Files)...)
+	return &msg, c.requestMessage(ctx, "POST", c.BaseURL+EndpointChannelMessages(cid), send, send.Files, &msg, reqOpts...)
+}
+
+func (c *client) ChannelMessage(ctx context.Context, cid, mid string) (*discordgo.Message, error) {
+	var msg discordgo.Message
+	return &msg, c.RequestJSON(ctx, "GET", c.BaseURL+EndpointChannelMessage(cid, mid), nil, &msg)
+}
+
+func (c *client) ChannelMessages(ctx context.Context, cid string, opts ...MessagesOpt) *Iterator[*discordgo.Message] {
+	var q MessagesQuery
 	for _, opt := range opts {
-		opt(&send)
+		opt(&q)
+	}
+
+	urlStr := c.BaseURL + EndpointChannelMessages(cid)
+	var fetch pageFunc[*discordgo.Message]
+	switch {
+	case q.Around != "":
+		// There's no way to page onwards from messages around another; return a single page.
+		fetch = func(ctx context.Context, cursor string, limit int) ([]*discordgo.Message, string, error) {
+			var msgs []*discordgo.Message
+			pq := MessagesQuery{Around: q.Around, Limit: limit}
+			return msgs, "", c.RequestJSON(ctx, "GET", urlStr+pq.Encode(), nil, &msgs)
+		}
+	case q.After != "":
+		fetch = pageSnowflakes(c, urlStr, nil, "after", q.After, messageID)
+	default:
+		fetch = pageSnowflakes(c, urlStr, nil, "before", q.Before, messageID)
+	}
+	return newIterator(ctx, 100, fetch).Limit(q.Limit)
+}
+
+func (c *client) ChannelMessageEdit(ctx context.Context, cid, mid string, opts ...EditOpt) (*discordgo.Message, error) {
+	edit := newMessageEdit(opts)
+	var msg discordgo.Message
+	return &msg, c.requestMessage(ctx, "PATCH", c.BaseURL+EndpointChannelMessage(cid, mid), edit, edit.Files, &msg)
+}
+
+func (c *client) ChannelMessageDelete(ctx context.Context, cid, mid string) error {
+	_, err := c.Request(ctx, "DELETE", c.BaseURL+EndpointChannelMessage(cid, mid), nil)
+	return err
+}
+
+func (c *client) ChannelMessagesBulkDelete(ctx context.Context, cid string, ids []string) ([]DeleteResult, error) {
+	// Sort out messages too old to be bulk deleted, with a bit of margin for slow requests.
+	cutoff := time.Now().Add(-BulkDeleteMaxAge + time.Minute)
+	seen := make(map[string]bool, len(ids))
+	var recent, old []string
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if ts, err := SnowflakeTime(id); err == nil && ts.After(cutoff) {
+			recent = append(recent, id)
+		} else {
+			old = append(old, id)
+		}
+	}
+
+	results := make([]DeleteResult, 0, len(seen))
+	var firstErr error
+	report := func(ids []string, err error) {
+		for _, id := range ids {
+			results = append(results, DeleteResult{ID: id, Err: err})
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
-	data, err := json.Marshal(send)
+
+	for len(recent) > 0 {
+		n := len(recent)
+		if n > BulkDeleteMaxMessages {
+			n = BulkDeleteMaxMessages
+		}
+		batch := recent[:n]
+		recent = recent[n:]
+
+		// Bulk deletes need at least 2 messages.
+		if len(batch) == 1 {
+			report(batch, c.ChannelMessageDelete(ctx, cid, batch[0]))
+			continue
+		}
+		data, err := json.Marshal(struct {
+			Messages []string `json:"messages"`
+		}{batch})
+		if err != nil {
+			return nil, err
+		}
+		_, err = c.Request(ctx, "POST", c.BaseURL+EndpointChannelMessagesBulkDelete(cid), data)
+		report(batch, err)
+	}
+	for _, id := range old {
+		report([]string{id}, c.ChannelMessageDelete(ctx, cid, id))
+	}
+
+	return results, firstErr
+}
+
+func (c *client) ChannelPurge(ctx context.Context, cid string, limit int, match func(msg *discordgo.Message) bool) ([]DeleteResult, error) {
+	var ids []string
+	it := c.ChannelMessages(ctx, cid)
+	for len(ids) < limit && it.Next() {
+		if msg := it.Value(); match == nil || match(msg) {
+			ids = append(ids, msg.ID)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return c.ChannelMessagesBulkDelete(ctx, cid, ids)
+}
+
+func (c *client) ChannelMessagePin(ctx context.Context, cid, mid string) error {
+	_, err := c.Request(ctx, "PUT", c.BaseURL+EndpointChannelPin(cid, mid), nil)
+	return err
+}
+
+func (c *client) ChannelMessageUnpin(ctx context.Context, cid, mid string) error {
+	_, err := c.Request(ctx, "DELETE", c.BaseURL+EndpointChannelPin(cid, mid), nil)
+	return err
+}
+
+func (c *client) ChannelMessagesPinned(ctx context.Context, cid string) ([]*discordgo.Message, error) {
+	var msgs []*discordgo.Message
+	return msgs, c.RequestJSON(ctx, "GET", c.BaseURL+EndpointChannelPins(cid), nil, &msgs)
+}
+
+// Sends a message payload as JSON, or as multipart/form-data if there are any files to upload.
+// The response is decoded into out, unless it is nil.
+func (c *client) requestMessage(ctx context.Context, method, urlStr string, payload interface{}, files []*File, out interface{}, opts ...ReqOption) error {
+	// Catch payloads Discord would reject with a vague error, eg. oversized embeds.
+	if v, ok := payload.(interface{ validate() error }); ok {
+		if err := v.validate(); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		data, err = c.Request(ctx, method, urlStr, data, opts...)
+	} else {
+		data, err = c.requestMultipart(ctx, method, urlStr, data, files, opts...)
+	}
+	if err != nil || out == nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// Sends a JSON payload as multipart/form-data, with files.
+func (c *client) requestMultipart(ctx context.Context, method, urlStr string, data []byte, files []*File, opts ...ReqOption) ([]byte, error) {
+	body := newMultipartBody(data, files)
+	req, err := http.NewRequest(method, urlStr, nil)
 	if err != nil {
 		return nil, err
 	}
-	var msg discordgo.Message
-	return &msg, c.RequestJSON(ctx, "POST", c.BaseURL+EndpointChannelMessages(cid), data, &msg)
+	req.ContentLength = -1
+	req.GetBody = body.Open
+	opts = append(opts, WithContentType(body.ContentType()))
+	if !body.Reopenable() {
+		// Streamed files can't be sent twice; return the first error rather than waiting to retry.
+		opts = append(opts, WithRateLimitRetries(0), WithRetryPolicy(RetryPolicy{}))
+	}
+	return c.do(ctx, req, opts...)
 }
 
 func (c *client) Gateway(ctx context.Context) (*Gateway, error) {
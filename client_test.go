@@ -2,11 +2,14 @@ package dgo2poc
 
 import (
 	"context"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -130,3 +133,224 @@ func TestClientUser(t *testing.T) {
 func TestClientToken(t *testing.T) {
 	assert.Equal(t, BotToken("hi"), NewClient(BotToken("hi")).Token())
 }
+
+func TestClientChannelMessage(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"id":"5678","content":"hi"}`)
+	defer srv.Close()
+
+	msg, err := cl.ChannelMessage(context.Background(), "1234", "5678")
+	require.NoError(t, err)
+	assert.Equal(t, "5678", msg.ID)
+	assert.Equal(t, "hi", msg.Content)
+	assert.Equal(t, "GET", rec.Last().Method)
+	assert.Equal(t, "/channels/1234/messages/5678", rec.Last().Path)
+}
+
+func TestClientChannelMessages(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `[{"id":"2"},{"id":"3"}]`)
+	defer srv.Close()
+
+	msgs, err := cl.ChannelMessages(context.Background(), "1234").All()
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, "3", msgs[0].ID)
+	assert.Equal(t, "2", msgs[1].ID)
+	assert.Equal(t, "GET", rec.Last().Method)
+	assert.Equal(t, "/channels/1234/messages", rec.Last().Path)
+	assert.Equal(t, "limit=100", rec.Last().Query)
+
+	t.Run("Opts", func(t *testing.T) {
+		_, err := cl.ChannelMessages(context.Background(), "1234", MessagesBefore("5678"), MessagesLimit(2)).All()
+		require.NoError(t, err)
+		assert.Equal(t, "before=5678&limit=2", rec.Last().Query)
+
+		msgs, err = cl.ChannelMessages(context.Background(), "1234", MessagesAfter("1")).All()
+		require.NoError(t, err)
+		assert.Equal(t, "after=1&limit=100", rec.Last().Query)
+		require.Len(t, msgs, 2)
+		assert.Equal(t, "2", msgs[0].ID)
+		assert.Equal(t, "3", msgs[1].ID)
+
+		_, err = cl.ChannelMessages(context.Background(), "1234", MessagesAround("5678")).All()
+		require.NoError(t, err)
+		assert.Equal(t, "around=5678&limit=100", rec.Last().Query)
+	})
+}
+
+func TestClientChannelMessageEdit(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"id":"5678","content":"hello"}`)
+	defer srv.Close()
+
+	msg, err := cl.ChannelMessageEdit(context.Background(), "1234", "5678", EditWithContent("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", msg.Content)
+	assert.Equal(t, "PATCH", rec.Last().Method)
+	assert.Equal(t, "/channels/1234/messages/5678", rec.Last().Path)
+	assert.JSONEq(t, `{"content":"hello"}`, rec.Last().Body)
+
+	t.Run("Embeds", func(t *testing.T) {
+		_, err := cl.ChannelMessageEdit(context.Background(), "1234", "5678",
+			EditWithEmbed(&discordgo.MessageEmbed{Title: "a"}),
+			EditWithEmbed(&discordgo.MessageEmbed{Title: "b"}),
+		)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"embeds":[{"title":"a"},{"title":"b"}]}`, rec.Last().Body)
+
+		_, err = cl.ChannelMessageEdit(context.Background(), "1234", "5678", EditWithoutEmbeds())
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"embeds":[]}`, rec.Last().Body)
+	})
+
+	t.Run("Attachments", func(t *testing.T) {
+		_, err := cl.ChannelMessageEdit(context.Background(), "1234", "5678", EditKeepAttachments("1111"))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"attachments":[{"id":"1111"}]}`, rec.Last().Body)
+	})
+}
+
+func TestClientChannelMessageDelete(t *testing.T) {
+	srv, cl, rec := newTestServer(t, "")
+	defer srv.Close()
+
+	require.NoError(t, cl.ChannelMessageDelete(context.Background(), "1234", "5678"))
+	assert.Equal(t, "DELETE", rec.Last().Method)
+	assert.Equal(t, "/channels/1234/messages/5678", rec.Last().Path)
+}
+
+func TestClientChannelMessagePins(t *testing.T) {
+	srv, cl, rec := newTestServer(t, "")
+	defer srv.Close()
+
+	require.NoError(t, cl.ChannelMessagePin(context.Background(), "1234", "5678"))
+	assert.Equal(t, "PUT", rec.Last().Method)
+	assert.Equal(t, "/channels/1234/pins/5678", rec.Last().Path)
+
+	require.NoError(t, cl.ChannelMessageUnpin(context.Background(), "1234", "5678"))
+	assert.Equal(t, "DELETE", rec.Last().Method)
+	assert.Equal(t, "/channels/1234/pins/5678", rec.Last().Path)
+
+	t.Run("List", func(t *testing.T) {
+		srv, cl, rec := newTestServer(t, `[{"id":"5678","pinned":true}]`)
+		defer srv.Close()
+
+		msgs, err := cl.ChannelMessagesPinned(context.Background(), "1234")
+		require.NoError(t, err)
+		require.Len(t, msgs, 1)
+		assert.True(t, msgs[0].Pinned)
+		assert.Equal(t, "GET", rec.Last().Method)
+		assert.Equal(t, "/channels/1234/pins", rec.Last().Path)
+	})
+}
+
+func TestClientChannelMessagesBulkDelete(t *testing.T) {
+	srv, cl, rec := newTestServer(t, "")
+	defer srv.Close()
+
+	// 150 recent messages, one of which is given twice, and one old one.
+	var ids, recent []string
+	for i := 0; i < 150; i++ {
+		id := SnowflakeFromTime(time.Now().Add(-time.Duration(i) * time.Minute))
+		ids = append(ids, id)
+		recent = append(recent, id)
+	}
+	ids = append(ids, recent[0])
+	old := SnowflakeFromTime(time.Now().Add(-15 * 24 * time.Hour))
+	ids = append(ids, old)
+
+	results, err := cl.ChannelMessagesBulkDelete(context.Background(), "1234", ids)
+	require.NoError(t, err)
+	require.Len(t, results, 151)
+	for i, id := range append(recent, old) {
+		assert.Equal(t, DeleteResult{ID: id}, results[i])
+	}
+
+	reqs := rec.All()
+	require.Len(t, reqs, 3)
+	assert.Equal(t, "POST", reqs[0].Method)
+	assert.Equal(t, "/channels/1234/messages/bulk-delete", reqs[0].Path)
+	var body struct {
+		Messages []string `json:"messages"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(reqs[0].Body), &body))
+	assert.Equal(t, recent[:100], body.Messages)
+	require.NoError(t, json.Unmarshal([]byte(reqs[1].Body), &body))
+	assert.Equal(t, recent[100:], body.Messages)
+	assert.Equal(t, "DELETE", reqs[2].Method)
+	assert.Equal(t, "/channels/1234/messages/"+old, reqs[2].Path)
+
+	t.Run("Single", func(t *testing.T) {
+		srv, cl, rec := newTestServer(t, "")
+		defer srv.Close()
+		_, err := cl.ChannelMessagesBulkDelete(context.Background(), "1234", recent[:1])
+		require.NoError(t, err)
+		assert.Equal(t, "DELETE", rec.Last().Method)
+		assert.Equal(t, "/channels/1234/messages/"+recent[0], rec.Last().Path)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.Method == "DELETE" {
+				rw.WriteHeader(http.StatusNotFound)
+				_, _ = rw.Write([]byte(`{"code":10008,"message":"Unknown Message"}`))
+				return
+			}
+			rw.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+		results, err := newTestClient(srv).ChannelMessagesBulkDelete(context.Background(), "1234", []string{recent[0], recent[1], old})
+		assert.EqualError(t, err, "404: Unknown Message")
+		require.Len(t, results, 3)
+		assert.NoError(t, results[0].Err)
+		assert.NoError(t, results[1].Err)
+		assert.Equal(t, APIErrorUnknownMessage, ErrorCode(results[2].Err))
+	})
+}
+
+func TestClientChannelPurge(t *testing.T) {
+	// 250 messages, newest first, every other one from a bot.
+	var msgs []*discordgo.Message
+	for i := 0; i < 250; i++ {
+		msgs = append(msgs, &discordgo.Message{
+			ID:     SnowflakeFromTime(time.Now().Add(-time.Duration(i) * time.Minute)),
+			Author: &discordgo.User{Bot: i%2 == 0},
+		})
+	}
+
+	var deleted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case "GET":
+			assert.Equal(t, "100", req.URL.Query().Get("limit"))
+			page := msgs
+			if before := req.URL.Query().Get("before"); before != "" {
+				for i, msg := range msgs {
+					if msg.ID == before {
+						page = msgs[i+1:]
+					}
+				}
+			}
+			if len(page) > 100 {
+				page = page[:100]
+			}
+			assert.NoError(t, json.NewEncoder(rw).Encode(page))
+		case "POST":
+			var body struct {
+				Messages []string `json:"messages"`
+			}
+			assert.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			deleted = append(deleted, body.Messages...)
+			rw.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	results, err := newTestClient(srv).ChannelPurge(context.Background(), "1234", 120, func(msg *discordgo.Message) bool {
+		return msg.Author.Bot
+	})
+	require.NoError(t, err)
+	assert.Len(t, results, 120)
+	require.Len(t, deleted, 120)
+	for i, id := range deleted {
+		assert.Equal(t, msgs[i*2].ID, id)
+	}
+}
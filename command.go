@@ -0,0 +1,359 @@
+package dgo2poc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// An action in a CommandPlan.
+type CommandAction string
+
+// Actions in a CommandPlan.
+const (
+	CommandCreate CommandAction = "create"
+	CommandUpdate CommandAction = "update"
+	CommandDelete CommandAction = "delete"
+)
+
+// A change to a registered command; see Client.SyncCommands().
+type CommandChange struct {
+	Action  CommandAction
+	GuildID string // "" for global commands.
+
+	// The command as it should be, or nil if it's being deleted.
+	Command *discordgo.ApplicationCommand
+
+	// The command as it's currently registered, or nil if it's being created.
+	Current *discordgo.ApplicationCommand
+}
+
+// Returns the command's name, as it'd be typed by a user.
+func (c CommandChange) String() string {
+	cmd := c.Command
+	if cmd == nil {
+		cmd = c.Current
+	}
+	scope := "global"
+	if c.GuildID != "" {
+		scope = "guild " + c.GuildID
+	}
+	return fmt.Sprintf("%s %s %s", c.Action, scope, commandDisplayName(cmd))
+}
+
+// Changes needed to bring registered commands in line with the desired ones; see Client.SyncCommands().
+type CommandPlan struct {
+	Changes   []CommandChange
+	Unchanged int // Number of commands that are already up to date.
+}
+
+// Returns a summary of the plan, one change per line.
+func (p *CommandPlan) String() string {
+	lines := make([]string, 0, len(p.Changes)+1)
+	for _, c := range p.Changes {
+		lines = append(lines, c.String())
+	}
+	lines = append(lines, fmt.Sprintf("%d unchanged", p.Unchanged))
+	return strings.Join(lines, "\n")
+}
+
+// Options for Client.SyncCommands().
+type SyncOptions struct {
+	DryRun    bool     // Only return a plan, don't make any changes.
+	Overwrite bool     // Apply changes with a bulk overwrite per scope, rather than one call per command.
+	Guilds    []string // Also sync these guilds, deleting their commands if none are given for them.
+	Global    bool     // Also sync global commands, deleting them if none are given.
+}
+
+// Options for Client.SyncCommands().
+type SyncOpt func(opts *SyncOptions)
+
+// Only return a plan, without making any changes.
+func SyncDryRun() SyncOpt {
+	return SyncOpt(func(opts *SyncOptions) {
+		opts.DryRun = true
+	})
+}
+
+// Apply changes with one bulk overwrite per scope that has any, rather than one call per command.
+// This is faster with many changes, but replaces the IDs of any commands it recreates.
+func SyncOverwrite() SyncOpt {
+	return SyncOpt(func(opts *SyncOptions) {
+		opts.Overwrite = true
+	})
+}
+
+// Also sync the given guilds, even if no commands are given for them; this deletes their commands.
+// Guilds that commands are given for are always synced.
+func SyncGuilds(ids ...string) SyncOpt {
+	return SyncOpt(func(opts *SyncOptions) {
+		opts.Guilds = append(opts.Guilds, ids...)
+	})
+}
+
+// Also sync global commands, even if none are given; this deletes them. Global commands are
+// otherwise only synced if at least one command without a GuildID is given.
+func SyncGlobal() SyncOpt {
+	return SyncOpt(func(opts *SyncOptions) {
+		opts.Global = true
+	})
+}
+
+func (c *client) ApplicationCommands(ctx context.Context, app, gid string) ([]*discordgo.ApplicationCommand, error) {
+	var cmds []*discordgo.ApplicationCommand
+	return cmds, c.RequestJSON(ctx, "GET", c.commandsURL(app, gid)+"?with_localizations=true", nil, &cmds)
+}
+
+func (c *client) ApplicationCommandCreate(ctx context.Context, app, gid string, cmd *discordgo.ApplicationCommand) (*discordgo.ApplicationCommand, error) {
+	return c.requestCommand(ctx, "POST", c.commandsURL(app, gid), cmd)
+}
+
+func (c *client) ApplicationCommandEdit(ctx context.Context, app, gid, id string, cmd *discordgo.ApplicationCommand) (*discordgo.ApplicationCommand, error) {
+	return c.requestCommand(ctx, "PATCH", c.commandsURL(app, gid)+"/"+id, cmd)
+}
+
+func (c *client) requestCommand(ctx context.Context, method, urlStr string, cmd *discordgo.ApplicationCommand) (*discordgo.ApplicationCommand, error) {
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, err
+	}
+	var out discordgo.ApplicationCommand
+	return &out, c.RequestJSON(ctx, method, urlStr, data, &out)
+}
+
+func (c *client) ApplicationCommandDelete(ctx context.Context, app, gid, id string) error {
+	_, err := c.Request(ctx, "DELETE", c.commandsURL(app, gid)+"/"+id, nil)
+	return err
+}
+
+func (c *client) ApplicationCommandsOverwrite(ctx context.Context, app, gid string, cmds []*discordgo.ApplicationCommand) ([]*discordgo.ApplicationCommand, error) {
+	if cmds == nil {
+		cmds = []*discordgo.ApplicationCommand{}
+	}
+	data, err := json.Marshal(cmds)
+	if err != nil {
+		return nil, err
+	}
+	var out []*discordgo.ApplicationCommand
+	return out, c.RequestJSON(ctx, "PUT", c.commandsURL(app, gid), data, &out)
+}
+
+func (c *client) ApplicationCommandPermissions(ctx context.Context, app, gid, id string) (*discordgo.GuildApplicationCommandPermissions, error) {
+	var perms discordgo.GuildApplicationCommandPermissions
+	return &perms, c.RequestJSON(ctx, "GET", c.BaseURL+EndpointApplicationCommandPermissions(app, gid, id), nil, &perms)
+}
+
+func (c *client) ApplicationCommandPermissionsEdit(ctx context.Context, app, gid, id string, perms []*discordgo.ApplicationCommandPermissions) (*discordgo.GuildApplicationCommandPermissions, error) {
+	data, err := json.Marshal(discordgo.ApplicationCommandPermissionsList{Permissions: perms})
+	if err != nil {
+		return nil, err
+	}
+	var out discordgo.GuildApplicationCommandPermissions
+	return &out, c.RequestJSON(ctx, "PUT", c.BaseURL+EndpointApplicationCommandPermissions(app, gid, id), data, &out)
+}
+
+// Returns the URL for an application's global commands, or a guild's if gid isn't "".
+func (c *client) commandsURL(app, gid string) string {
+	if gid == "" {
+		return c.BaseURL + EndpointApplicationCommands(app)
+	}
+	return c.BaseURL + EndpointApplicationGuildCommands(app, gid)
+}
+
+func (c *client) SyncCommands(ctx context.Context, app string, cmds []*discordgo.ApplicationCommand, opts ...SyncOpt) (*CommandPlan, error) {
+	var sopts SyncOptions
+	for _, opt := range opts {
+		opt(&sopts)
+	}
+
+	// Group desired commands by scope. Scopes nothing is given for are left alone, unless explicitly
+	// requested; otherwise, syncing only guild commands would delete all global ones.
+	scopes := map[string][]*discordgo.ApplicationCommand{}
+	if sopts.Global {
+		scopes[""] = nil
+	}
+	for _, gid := range sopts.Guilds {
+		if _, ok := scopes[gid]; !ok {
+			scopes[gid] = nil
+		}
+	}
+	for _, cmd := range cmds {
+		scopes[cmd.GuildID] = append(scopes[cmd.GuildID], cmd)
+	}
+	gids := make([]string, 0, len(scopes))
+	for gid := range scopes {
+		gids = append(gids, gid)
+	}
+	sort.Strings(gids)
+
+	plan := &CommandPlan{}
+	for _, gid := range gids {
+		current, err := c.ApplicationCommands(ctx, app, gid)
+		if err != nil {
+			return plan, err
+		}
+		changes, unchanged := planCommands(gid, scopes[gid], current)
+		plan.Unchanged += unchanged
+		if sopts.DryRun || len(changes) == 0 {
+			plan.Changes = append(plan.Changes, changes...)
+			continue
+		}
+
+		if sopts.Overwrite {
+			if _, err := c.ApplicationCommandsOverwrite(ctx, app, gid, scopes[gid]); err != nil {
+				return plan, err
+			}
+			plan.Changes = append(plan.Changes, changes...)
+			continue
+		}
+		for _, change := range changes {
+			if err := c.applyCommandChange(ctx, app, change); err != nil {
+				return plan, err
+			}
+			plan.Changes = append(plan.Changes, change)
+		}
+	}
+	return plan, nil
+}
+
+// Applies a single change from a CommandPlan.
+func (c *client) applyCommandChange(ctx context.Context, app string, change CommandChange) error {
+	var err error
+	switch change.Action {
+	case CommandCreate:
+		_, err = c.ApplicationCommandCreate(ctx, app, change.GuildID, change.Command)
+	case CommandUpdate:
+		_, err = c.ApplicationCommandEdit(ctx, app, change.GuildID, change.Current.ID, change.Command)
+	case CommandDelete:
+		err = c.ApplicationCommandDelete(ctx, app, change.GuildID, change.Current.ID)
+	}
+	return err
+}
+
+// Diffs desired commands in a scope against registered ones. Commands are matched by type and name.
+// Deletes are planned first, so replacing commands doesn't exceed Discord's per-scope limits.
+func planCommands(gid string, want, have []*discordgo.ApplicationCommand) ([]CommandChange, int) {
+	byKey := make(map[string]*discordgo.ApplicationCommand, len(have))
+	for _, cmd := range have {
+		byKey[commandKey(cmd)] = cmd
+	}
+	wanted := make(map[string]bool, len(want))
+	var creates, updates, deletes []CommandChange
+	var unchanged int
+	for _, cmd := range want {
+		key := commandKey(cmd)
+		wanted[key] = true
+		current, ok := byKey[key]
+		switch {
+		case !ok:
+			creates = append(creates, CommandChange{Action: CommandCreate, GuildID: gid, Command: cmd})
+		case !commandsEqual(cmd, current, gid == ""):
+			updates = append(updates, CommandChange{Action: CommandUpdate, GuildID: gid, Command: cmd, Current: current})
+		default:
+			unchanged++
+		}
+	}
+	for _, cmd := range have {
+		if !wanted[commandKey(cmd)] {
+			deletes = append(deletes, CommandChange{Action: CommandDelete, GuildID: gid, Current: cmd})
+		}
+	}
+	return append(append(deletes, updates...), creates...), unchanged
+}
+
+// Returns a key identifying a command within a scope; names are unique per type.
+func commandKey(cmd *discordgo.ApplicationCommand) string {
+	typ := cmd.Type
+	if typ == 0 {
+		typ = discordgo.ChatApplicationCommand
+	}
+	return fmt.Sprintf("%d:%s", typ, cmd.Name)
+}
+
+// Returns a command's name, prefixed with a "/" for slash commands.
+func commandDisplayName(cmd *discordgo.ApplicationCommand) string {
+	if cmd.Type == 0 || cmd.Type == discordgo.ChatApplicationCommand {
+		return "/" + cmd.Name
+	}
+	return cmd.Name
+}
+
+// Returns whether a desired command matches a registered one, ignoring fields Discord fills in.
+func commandsEqual(want, have *discordgo.ApplicationCommand, global bool) bool {
+	a, err := json.Marshal(normalizeCommand(want, global))
+	if err != nil {
+		return false
+	}
+	b, err := json.Marshal(normalizeCommand(have, global))
+	if err != nil {
+		return false
+	}
+	return string(a) == string(b)
+}
+
+// Returns a copy of a command with server-assigned fields cleared and defaults filled in.
+func normalizeCommand(cmd *discordgo.ApplicationCommand, global bool) *discordgo.ApplicationCommand {
+	out := *cmd
+	out.ID, out.ApplicationID, out.GuildID, out.Version = "", "", "", ""
+	out.DefaultPermission = nil
+	if out.Type == 0 {
+		out.Type = discordgo.ChatApplicationCommand
+	}
+	if out.NameLocalizations != nil && len(*out.NameLocalizations) == 0 {
+		out.NameLocalizations = nil
+	}
+	if out.DescriptionLocalizations != nil && len(*out.DescriptionLocalizations) == 0 {
+		out.DescriptionLocalizations = nil
+	}
+	if out.NSFW != nil && !*out.NSFW {
+		out.NSFW = nil
+	}
+
+	// DMs are allowed by default, and the setting only applies to global commands.
+	if !global || (out.DMPermission != nil && *out.DMPermission) {
+		out.DMPermission = nil
+	}
+	out.Options = normalizeCommandOptions(out.Options)
+	return &out
+}
+
+// Returns normalised copies of command options; see normalizeCommand().
+func normalizeCommandOptions(opts []*discordgo.ApplicationCommandOption) []*discordgo.ApplicationCommandOption {
+	if len(opts) == 0 {
+		return nil
+	}
+	out := make([]*discordgo.ApplicationCommandOption, len(opts))
+	for i, opt := range opts {
+		o := *opt
+		if len(o.NameLocalizations) == 0 {
+			o.NameLocalizations = nil
+		}
+		if len(o.DescriptionLocalizations) == 0 {
+			o.DescriptionLocalizations = nil
+		}
+		if len(o.ChannelTypes) == 0 {
+			o.ChannelTypes = nil
+		}
+		if len(o.Choices) == 0 {
+			o.Choices = nil
+		} else {
+			o.Choices = make([]*discordgo.ApplicationCommandOptionChoice, len(opt.Choices))
+			for j, choice := range opt.Choices {
+				ch := *choice
+				if len(ch.NameLocalizations) == 0 {
+					ch.NameLocalizations = nil
+				}
+				// Numbers are decoded as float64; make them compare equal to ints.
+				ch.Value = fmt.Sprint(ch.Value)
+				o.Choices[j] = &ch
+			}
+		}
+		o.Options = normalizeCommandOptions(o.Options)
+		out[i] = &o
+	}
+	return out
+}
@@ -0,0 +1,286 @@
+package dgo2poc
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pkg/errors"
+)
+
+// Types command options can be bound to, other than basic types; see HandleCommand().
+var (
+	typeUser       = reflect.TypeOf((*discordgo.User)(nil))
+	typeMember     = reflect.TypeOf((*discordgo.Member)(nil))
+	typeChannel    = reflect.TypeOf((*discordgo.Channel)(nil))
+	typeRole       = reflect.TypeOf((*discordgo.Role)(nil))
+	typeAttachment = reflect.TypeOf((*discordgo.MessageAttachment)(nil))
+)
+
+// A struct field bound to a command option.
+type commandOptionField struct {
+	Index  int
+	Option *discordgo.ApplicationCommandOption
+}
+
+// Describes how a struct's fields are bound to a command's options.
+type commandOptionsSpec struct {
+	Type   reflect.Type
+	Fields []commandOptionField
+}
+
+// Parses a struct's option tags. Each exported field with an "option" tag becomes an option;
+// as Discord requires, required options must come before optional ones:
+//
+//	Name  string `option:"name,required" description:"Your name" max:"32"`
+//	Count int    `option:"count" description:"How many" min:"1" max:"10"`
+//	Color string `option:"color" description:"A colour" choices:"Red=red,Green=green,Blue=blue"`
+func parseCommandOptions(t reflect.Type) (*commandOptionsSpec, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, errors.Errorf("options must be a struct, not %s", t)
+	}
+	spec := &commandOptionsSpec{Type: t}
+	var optional string // The first optional option, if any.
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("option")
+		if !ok || !f.IsExported() {
+			continue
+		}
+		opt, err := parseCommandOption(f, tag)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s.%s", t.Name(), f.Name)
+		}
+		if !opt.Required && optional == "" {
+			optional = f.Name
+		} else if opt.Required && optional != "" {
+			return nil, errors.Errorf("%s.%s: required options must come before optional ones, like %s",
+				t.Name(), f.Name, optional)
+		}
+		spec.Fields = append(spec.Fields, commandOptionField{Index: i, Option: opt})
+	}
+	return spec, nil
+}
+
+// Parses a single field's option tags.
+func parseCommandOption(f reflect.StructField, tag string) (*discordgo.ApplicationCommandOption, error) {
+	name, flags, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = strings.ToLower(f.Name)
+	}
+	opt := &discordgo.ApplicationCommandOption{Name: name, Description: f.Tag.Get("description")}
+	if opt.Description == "" {
+		opt.Description = name
+	}
+	for _, flag := range strings.Split(flags, ",") {
+		switch flag {
+		case "":
+		case "required":
+			opt.Required = true
+		case "autocomplete":
+			opt.Autocomplete = true
+		default:
+			return nil, errors.Errorf("unknown flag: %s", flag)
+		}
+	}
+
+	typ, ok := commandOptionType(f.Type)
+	if !ok {
+		return nil, errors.Errorf("unsupported type: %s", f.Type)
+	}
+	opt.Type = typ
+
+	for _, lim := range []string{"min", "max"} {
+		s, ok := f.Tag.Lookup(lim)
+		if !ok {
+			continue
+		}
+		switch typ {
+		case discordgo.ApplicationCommandOptionString:
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				return nil, errors.Wrap(err, lim)
+			}
+			if lim == "min" {
+				opt.MinLength = &n
+			} else {
+				opt.MaxLength = n
+			}
+		case discordgo.ApplicationCommandOptionInteger, discordgo.ApplicationCommandOptionNumber:
+			n, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, errors.Wrap(err, lim)
+			}
+			if lim == "min" {
+				opt.MinValue = &n
+			} else {
+				opt.MaxValue = n
+			}
+		default:
+			return nil, errors.Errorf("%s isn't supported for %s options", lim, typ)
+		}
+	}
+
+	if s := f.Tag.Get("choices"); s != "" {
+		for _, c := range strings.Split(s, ",") {
+			name, value, ok := strings.Cut(c, "=")
+			if !ok {
+				value = name
+			}
+			v, err := parseCommandOptionValue(typ, value)
+			if err != nil {
+				return nil, errors.Wrap(err, "choices")
+			}
+			opt.Choices = append(opt.Choices, &discordgo.ApplicationCommandOptionChoice{Name: name, Value: v})
+		}
+	}
+	return opt, nil
+}
+
+// Returns the option type for a field type.
+func commandOptionType(t reflect.Type) (discordgo.ApplicationCommandOptionType, bool) {
+	switch t {
+	case typeUser, typeMember:
+		return discordgo.ApplicationCommandOptionUser, true
+	case typeChannel:
+		return discordgo.ApplicationCommandOptionChannel, true
+	case typeRole:
+		return discordgo.ApplicationCommandOptionRole, true
+	case typeAttachment:
+		return discordgo.ApplicationCommandOptionAttachment, true
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return discordgo.ApplicationCommandOptionString, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return discordgo.ApplicationCommandOptionInteger, true
+	case reflect.Float32, reflect.Float64:
+		return discordgo.ApplicationCommandOptionNumber, true
+	case reflect.Bool:
+		return discordgo.ApplicationCommandOptionBoolean, true
+	}
+	return 0, false
+}
+
+// Parses a choice value for an option type.
+func parseCommandOptionValue(typ discordgo.ApplicationCommandOptionType, s string) (interface{}, error) {
+	switch typ {
+	case discordgo.ApplicationCommandOptionString:
+		return s, nil
+	case discordgo.ApplicationCommandOptionInteger:
+		return strconv.ParseInt(s, 10, 64)
+	case discordgo.ApplicationCommandOptionNumber:
+		return strconv.ParseFloat(s, 64)
+	}
+	return nil, errors.Errorf("choices aren't supported for %s options", typ)
+}
+
+// Returns the options to register.
+func (spec *commandOptionsSpec) Options() []*discordgo.ApplicationCommandOption {
+	opts := make([]*discordgo.ApplicationCommandOption, len(spec.Fields))
+	for i, f := range spec.Fields {
+		opts[i] = f.Option
+	}
+	return opts
+}
+
+// Binds received options to a pointer to a struct of the spec's type.
+func (spec *commandOptionsSpec) Bind(ptr interface{}, opts []*discordgo.ApplicationCommandInteractionDataOption, res *discordgo.ApplicationCommandInteractionDataResolved) error {
+	return spec.bind(ptr, opts, res, false)
+}
+
+// Binds the options of an autocomplete interaction, which may be incomplete or invalid. The focused
+// option, and any others that can't be bound, are left unset.
+func (spec *commandOptionsSpec) BindPartial(ptr interface{}, opts []*discordgo.ApplicationCommandInteractionDataOption, res *discordgo.ApplicationCommandInteractionDataResolved) {
+	_ = spec.bind(ptr, opts, res, true)
+}
+
+func (spec *commandOptionsSpec) bind(ptr interface{}, opts []*discordgo.ApplicationCommandInteractionDataOption, res *discordgo.ApplicationCommandInteractionDataResolved, partial bool) error {
+	v := reflect.ValueOf(ptr).Elem()
+	for _, f := range spec.Fields {
+		for _, opt := range opts {
+			if opt.Name != f.Option.Name || (partial && opt.Focused) {
+				continue
+			}
+			field := v.Field(f.Index)
+			if err := bindCommandOption(field, opt, res); err != nil {
+				if partial {
+					field.Set(reflect.Zero(field.Type()))
+					continue
+				}
+				return errors.Wrap(err, opt.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Binds a received option to a field.
+func bindCommandOption(field reflect.Value, opt *discordgo.ApplicationCommandInteractionDataOption, res *discordgo.ApplicationCommandInteractionDataResolved) error {
+	if res == nil {
+		res = &discordgo.ApplicationCommandInteractionDataResolved{}
+	}
+
+	// Resolved objects are looked up by ID.
+	var id string
+	switch field.Type() {
+	case typeUser, typeMember, typeChannel, typeRole, typeAttachment:
+		var ok bool
+		if id, ok = opt.Value.(string); !ok {
+			return errors.Errorf("expected an ID, got: %v", opt.Value)
+		}
+	}
+	switch field.Type() {
+	case typeUser:
+		field.Set(reflect.ValueOf(res.Users[id]))
+		return nil
+	case typeMember:
+		member := res.Members[id]
+		if member != nil && member.User == nil {
+			member.User = res.Users[id]
+		}
+		field.Set(reflect.ValueOf(member))
+		return nil
+	case typeChannel:
+		field.Set(reflect.ValueOf(res.Channels[id]))
+		return nil
+	case typeRole:
+		field.Set(reflect.ValueOf(res.Roles[id]))
+		return nil
+	case typeAttachment:
+		field.Set(reflect.ValueOf(res.Attachments[id]))
+		return nil
+	}
+
+	// Optional options may be bound to pointers, which are left nil if they're not given.
+	if field.Kind() == reflect.Ptr {
+		field.Set(reflect.New(field.Type().Elem()))
+		field = field.Elem()
+	}
+	switch v := opt.Value.(type) {
+	case string:
+		if field.Kind() == reflect.String {
+			field.SetString(v)
+			return nil
+		}
+	case float64:
+		switch field.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			field.SetInt(int64(v))
+			return nil
+		case reflect.Float32, reflect.Float64:
+			field.SetFloat(v)
+			return nil
+		}
+	case bool:
+		if field.Kind() == reflect.Bool {
+			field.SetBool(v)
+			return nil
+		}
+	}
+	return errors.Errorf("can't bind %T to %s", opt.Value, field.Type())
+}
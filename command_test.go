@@ -0,0 +1,267 @@
+package dgo2poc
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// A fake command registry, which fills in fields like Discord does.
+type testCommandRegistry struct {
+	mu     sync.Mutex
+	nextID int
+	scopes map[string][]*discordgo.ApplicationCommand // by guild ID, "" for global
+	calls  []string
+}
+
+func newTestCommandRegistry() *testCommandRegistry {
+	return &testCommandRegistry{nextID: 100, scopes: map[string][]*discordgo.ApplicationCommand{}}
+}
+
+// Registers a command as if it was created earlier.
+func (r *testCommandRegistry) add(gid string, cmd *discordgo.ApplicationCommand) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scopes[gid] = append(r.scopes[gid], r.fill(gid, cmd))
+}
+
+// Fills in server-assigned fields.
+func (r *testCommandRegistry) fill(gid string, cmd *discordgo.ApplicationCommand) *discordgo.ApplicationCommand {
+	out := *cmd
+	r.nextID++
+	out.ID = strconv.Itoa(r.nextID)
+	out.ApplicationID = "1111"
+	out.GuildID = gid
+	out.Version = "1"
+	if out.Type == 0 {
+		out.Type = discordgo.ChatApplicationCommand
+	}
+	if gid == "" && out.DMPermission == nil {
+		dm := true
+		out.DMPermission = &dm
+	}
+	return &out
+}
+
+func (r *testCommandRegistry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, req.Method+" "+req.URL.Path)
+
+	// /applications/1111/commands[/id] or /applications/1111/guilds/:gid/commands[/id]
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")[2:]
+	var gid, id string
+	if parts[0] == "guilds" {
+		gid, parts = parts[1], parts[2:]
+	}
+	if len(parts) > 1 {
+		id = parts[1]
+	}
+	body, _ := ioutil.ReadAll(req.Body)
+
+	var res interface{}
+	switch req.Method {
+	case "GET":
+		res = r.scopes[gid]
+		if r.scopes[gid] == nil {
+			res = []string{}
+		}
+	case "POST":
+		var cmd discordgo.ApplicationCommand
+		_ = json.Unmarshal(body, &cmd)
+		out := r.fill(gid, &cmd)
+		r.scopes[gid] = append(r.scopes[gid], out)
+		res = out
+	case "PATCH":
+		var cmd discordgo.ApplicationCommand
+		_ = json.Unmarshal(body, &cmd)
+		for i, c := range r.scopes[gid] {
+			if c.ID == id {
+				out := r.fill(gid, &cmd)
+				out.ID = id
+				r.scopes[gid][i] = out
+				res = out
+			}
+		}
+	case "PUT":
+		var cmds []*discordgo.ApplicationCommand
+		_ = json.Unmarshal(body, &cmds)
+		r.scopes[gid] = nil
+		for _, cmd := range cmds {
+			r.scopes[gid] = append(r.scopes[gid], r.fill(gid, cmd))
+		}
+		res = r.scopes[gid]
+	case "DELETE":
+		for i, c := range r.scopes[gid] {
+			if c.ID == id {
+				r.scopes[gid] = append(r.scopes[gid][:i], r.scopes[gid][i+1:]...)
+				break
+			}
+		}
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+	_ = json.NewEncoder(rw).Encode(res)
+}
+
+func TestClientApplicationCommands(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `[{"id":"5678","name":"ping"}]`)
+	defer srv.Close()
+	ctx := context.Background()
+
+	cmds, err := cl.ApplicationCommands(ctx, "1111", "")
+	require.NoError(t, err)
+	assert.Equal(t, "/applications/1111/commands", rec.Last().Path)
+	assert.Equal(t, "with_localizations=true", rec.Last().Query)
+	require.Len(t, cmds, 1)
+
+	_, err = cl.ApplicationCommands(ctx, "1111", "1234")
+	require.NoError(t, err)
+	assert.Equal(t, "/applications/1111/guilds/1234/commands", rec.Last().Path)
+
+	_, err = cl.ApplicationCommandsOverwrite(ctx, "1111", "1234", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "PUT", rec.Last().Method)
+	assert.Equal(t, "[]", rec.Last().Body)
+}
+
+func TestClientApplicationCommandCRUD(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"id":"5678","name":"ping"}`)
+	defer srv.Close()
+	ctx := context.Background()
+	cmd := &discordgo.ApplicationCommand{Name: "ping", Description: "Pings"}
+
+	_, err := cl.ApplicationCommandCreate(ctx, "1111", "", cmd)
+	require.NoError(t, err)
+	assert.Equal(t, "POST", rec.Last().Method)
+	assert.Equal(t, "/applications/1111/commands", rec.Last().Path)
+
+	_, err = cl.ApplicationCommandEdit(ctx, "1111", "1234", "5678", cmd)
+	require.NoError(t, err)
+	assert.Equal(t, "PATCH", rec.Last().Method)
+	assert.Equal(t, "/applications/1111/guilds/1234/commands/5678", rec.Last().Path)
+
+	require.NoError(t, cl.ApplicationCommandDelete(ctx, "1111", "", "5678"))
+	assert.Equal(t, "DELETE", rec.Last().Method)
+	assert.Equal(t, "/applications/1111/commands/5678", rec.Last().Path)
+}
+
+func TestClientApplicationCommandPermissions(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"id":"5678","permissions":[{"id":"9999","type":1,"permission":true}]}`)
+	defer srv.Close()
+	ctx := context.Background()
+
+	perms, err := cl.ApplicationCommandPermissions(ctx, "1111", "1234", "5678")
+	require.NoError(t, err)
+	assert.Equal(t, "/applications/1111/guilds/1234/commands/5678/permissions", rec.Last().Path)
+	require.Len(t, perms.Permissions, 1)
+
+	_, err = cl.ApplicationCommandPermissionsEdit(ctx, "1111", "1234", "5678", []*discordgo.ApplicationCommandPermissions{
+		{ID: "9999", Type: discordgo.ApplicationCommandPermissionTypeRole, Permission: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "PUT", rec.Last().Method)
+	assert.JSONEq(t, `{"permissions":[{"id":"9999","type":1,"permission":true}]}`, rec.Last().Body)
+}
+
+func TestClientSyncCommands(t *testing.T) {
+	reg := newTestCommandRegistry()
+	srv := httptest.NewServer(reg)
+	defer srv.Close()
+	cl := newTestClient(srv)
+	ctx := context.Background()
+
+	minLen := 1
+	reg.add("", &discordgo.ApplicationCommand{Name: "ping", Description: "Pings"})
+	reg.add("", &discordgo.ApplicationCommand{Name: "echo", Description: "Old description"})
+	reg.add("", &discordgo.ApplicationCommand{Name: "old", Description: "Removed"})
+	reg.add("", &discordgo.ApplicationCommand{Name: "Report", Type: discordgo.MessageApplicationCommand})
+	reg.add("1234", &discordgo.ApplicationCommand{Name: "stale", Description: "Removed"})
+
+	cmds := []*discordgo.ApplicationCommand{
+		{Name: "ping", Description: "Pings"},
+		{Name: "echo", Description: "Echoes", Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionString, Name: "text", Description: "Text", Required: true, MinLength: &minLen},
+		}},
+		{Name: "Report", Type: discordgo.MessageApplicationCommand},
+		{Name: "admin", Description: "Admin stuff", GuildID: "5678", NameLocalizations: &map[discordgo.Locale]string{
+			discordgo.German: "verwaltung",
+		}},
+	}
+
+	plan, err := cl.SyncCommands(ctx, "1111", cmds, SyncDryRun(), SyncGuilds("1234"))
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"delete global /old",
+		"update global /echo",
+		"delete guild 1234 /stale",
+		"create guild 5678 /admin",
+		"2 unchanged",
+	}, "\n"), plan.String())
+	for _, call := range reg.calls {
+		assert.True(t, strings.HasPrefix(call, "GET "), "dry run made a change: %s", call)
+	}
+
+	t.Run("Apply", func(t *testing.T) {
+		reg.calls = nil
+		plan, err := cl.SyncCommands(ctx, "1111", cmds, SyncGuilds("1234"))
+		require.NoError(t, err)
+		assert.Len(t, plan.Changes, 4)
+		assert.Equal(t, []string{
+			"GET /applications/1111/commands",
+			"DELETE /applications/1111/commands/103",
+			"PATCH /applications/1111/commands/102",
+			"GET /applications/1111/guilds/1234/commands",
+			"DELETE /applications/1111/guilds/1234/commands/105",
+			"GET /applications/1111/guilds/5678/commands",
+			"POST /applications/1111/guilds/5678/commands",
+		}, reg.calls)
+	})
+
+	t.Run("Idempotent", func(t *testing.T) {
+		reg.calls = nil
+		plan, err := cl.SyncCommands(ctx, "1111", cmds, SyncGuilds("1234"))
+		require.NoError(t, err)
+		assert.Empty(t, plan.Changes)
+		assert.Equal(t, 4, plan.Unchanged)
+		assert.Len(t, reg.calls, 3)
+	})
+
+	t.Run("Overwrite", func(t *testing.T) {
+		reg.calls = nil
+		plan, err := cl.SyncCommands(ctx, "1111", cmds[:1], SyncOverwrite())
+		require.NoError(t, err)
+		assert.Len(t, plan.Changes, 2)
+		assert.Equal(t, []string{
+			"GET /applications/1111/commands",
+			"PUT /applications/1111/commands",
+		}, reg.calls)
+		assert.Len(t, reg.scopes[""], 1)
+	})
+
+	t.Run("GuildOnly", func(t *testing.T) {
+		reg.calls = nil
+		plan, err := cl.SyncCommands(ctx, "1111", cmds[3:])
+		require.NoError(t, err)
+		assert.Empty(t, plan.Changes)
+		assert.Equal(t, []string{"GET /applications/1111/guilds/5678/commands"}, reg.calls)
+		assert.Len(t, reg.scopes[""], 1, "global commands should survive")
+	})
+
+	t.Run("Global", func(t *testing.T) {
+		reg.calls = nil
+		plan, err := cl.SyncCommands(ctx, "1111", cmds[3:], SyncGlobal(), SyncDryRun())
+		require.NoError(t, err)
+		assert.Equal(t, "delete global /ping\n1 unchanged", plan.String())
+	})
+}
@@ -0,0 +1,194 @@
+package dgo2poc
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Component limits: a message can have up to 5 action rows, each with up to 5 buttons or 1 select menu;
+// see ValidateComponents().
+const (
+	MessageMaxActionRows   = 5
+	ActionRowMaxComponents = 5
+	SelectMenuMaxOptions   = 25
+	CustomIDMaxLength      = 100
+)
+
+// Checks a message's components against Discord's limits. Returns a *ComponentLimitError for the
+// first limit that's exceeded, naming it like the API does, eg. "components.0.components".
+func ValidateComponents(components ...discordgo.MessageComponent) error {
+	if len(components) > MessageMaxActionRows {
+		return &ComponentLimitError{Field: "components", Length: len(components), Max: MessageMaxActionRows}
+	}
+	for i, c := range components {
+		var row discordgo.ActionsRow
+		switch c := c.(type) {
+		case discordgo.ActionsRow:
+			row = c
+		case *discordgo.ActionsRow:
+			row = *c
+		default:
+			continue
+		}
+		prefix := fmt.Sprintf("components.%d.components", i)
+		max := ActionRowMaxComponents
+		for _, rc := range row.Components {
+			switch rc.(type) {
+			case discordgo.SelectMenu, *discordgo.SelectMenu:
+				max = 1
+			}
+		}
+		if len(row.Components) > max {
+			return &ComponentLimitError{Field: prefix, Length: len(row.Components), Max: max}
+		}
+		for j, rc := range row.Components {
+			if err := validateComponent(fmt.Sprintf("%s.%d.", prefix, j), rc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Checks a button or select menu's custom ID and options.
+func validateComponent(prefix string, c discordgo.MessageComponent) error {
+	var customID string
+	switch c := c.(type) {
+	case discordgo.Button:
+		customID = c.CustomID
+	case *discordgo.Button:
+		customID = c.CustomID
+	case discordgo.SelectMenu:
+		return validateSelectMenu(prefix, &c)
+	case *discordgo.SelectMenu:
+		return validateSelectMenu(prefix, c)
+	}
+	if n := utf8.RuneCountInString(customID); n > CustomIDMaxLength {
+		return &ComponentLimitError{Field: prefix + "custom_id", Length: n, Max: CustomIDMaxLength}
+	}
+	return nil
+}
+
+func validateSelectMenu(prefix string, s *discordgo.SelectMenu) error {
+	if n := utf8.RuneCountInString(s.CustomID); n > CustomIDMaxLength {
+		return &ComponentLimitError{Field: prefix + "custom_id", Length: n, Max: CustomIDMaxLength}
+	}
+	if len(s.Options) > SelectMenuMaxOptions {
+		return &ComponentLimitError{Field: prefix + "options", Length: len(s.Options), Max: SelectMenuMaxOptions}
+	}
+	return nil
+}
+
+// Returns an action row, which holds up to 5 buttons or a single select menu.
+func ActionRow(components ...discordgo.MessageComponent) discordgo.ActionsRow {
+	return discordgo.ActionsRow{Components: components}
+}
+
+// Options for Button() and LinkButton().
+type ButtonOpt func(b *discordgo.Button)
+
+// Show an emoji on a button, eg. discordgo.ComponentEmoji{Name: "🐱"}.
+func ButtonWithEmoji(emoji discordgo.ComponentEmoji) ButtonOpt {
+	return ButtonOpt(func(b *discordgo.Button) {
+		b.Emoji = emoji
+	})
+}
+
+// Disable a button, so it can't be pressed.
+func ButtonDisabled() ButtonOpt {
+	return ButtonOpt(func(b *discordgo.Button) {
+		b.Disabled = true
+	})
+}
+
+// Returns a button, which sends an interaction with the given custom ID when pressed.
+func Button(style discordgo.ButtonStyle, label, customID string, opts ...ButtonOpt) discordgo.Button {
+	b := discordgo.Button{Style: style, Label: label, CustomID: customID}
+	for _, opt := range opts {
+		opt(&b)
+	}
+	return b
+}
+
+// Returns a button that opens a URL. These don't send interactions.
+func LinkButton(label, url string, opts ...ButtonOpt) discordgo.Button {
+	b := discordgo.Button{Style: discordgo.LinkButton, Label: label, URL: url}
+	for _, opt := range opts {
+		opt(&b)
+	}
+	return b
+}
+
+// Options for select menus, eg. StringSelect().
+type SelectOpt func(s *discordgo.SelectMenu)
+
+// Show placeholder text when nothing is selected.
+func SelectWithPlaceholder(text string) SelectOpt {
+	return SelectOpt(func(s *discordgo.SelectMenu) {
+		s.Placeholder = text
+	})
+}
+
+// Set how many values can be selected; the default is exactly one.
+func SelectValues(min, max int) SelectOpt {
+	return SelectOpt(func(s *discordgo.SelectMenu) {
+		s.MinValues = &min
+		s.MaxValues = max
+	})
+}
+
+// Only allow channels of the given types to be selected. Only works with ChannelSelect().
+func SelectChannelTypes(types ...discordgo.ChannelType) SelectOpt {
+	return SelectOpt(func(s *discordgo.SelectMenu) {
+		s.ChannelTypes = append(s.ChannelTypes, types...)
+	})
+}
+
+// Disable a select menu.
+func SelectDisabled() SelectOpt {
+	return SelectOpt(func(s *discordgo.SelectMenu) {
+		s.Disabled = true
+	})
+}
+
+// Returns a select menu option; see StringSelect().
+func SelectOption(label, value string) discordgo.SelectMenuOption {
+	return discordgo.SelectMenuOption{Label: label, Value: value}
+}
+
+// Returns a select menu with the given options, up to SelectMenuMaxOptions.
+func StringSelect(customID string, options []discordgo.SelectMenuOption, opts ...SelectOpt) discordgo.SelectMenu {
+	s := newSelectMenu(discordgo.StringSelectMenu, customID, opts)
+	s.Options = options
+	return s
+}
+
+// Returns a select menu for users; see ComponentInteraction.Users().
+func UserSelect(customID string, opts ...SelectOpt) discordgo.SelectMenu {
+	return newSelectMenu(discordgo.UserSelectMenu, customID, opts)
+}
+
+// Returns a select menu for roles; see ComponentInteraction.Roles().
+func RoleSelect(customID string, opts ...SelectOpt) discordgo.SelectMenu {
+	return newSelectMenu(discordgo.RoleSelectMenu, customID, opts)
+}
+
+// Returns a select menu for users and roles; see ComponentInteraction.Users() and Roles().
+func MentionableSelect(customID string, opts ...SelectOpt) discordgo.SelectMenu {
+	return newSelectMenu(discordgo.MentionableSelectMenu, customID, opts)
+}
+
+// Returns a select menu for channels; see ComponentInteraction.Channels().
+func ChannelSelect(customID string, opts ...SelectOpt) discordgo.SelectMenu {
+	return newSelectMenu(discordgo.ChannelSelectMenu, customID, opts)
+}
+
+func newSelectMenu(typ discordgo.SelectMenuType, customID string, opts []SelectOpt) discordgo.SelectMenu {
+	s := discordgo.SelectMenu{MenuType: typ, CustomID: customID}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
+}
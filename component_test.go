@@ -0,0 +1,127 @@
+package dgo2poc
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponents(t *testing.T) {
+	for name, tc := range map[string]struct {
+		Component discordgo.MessageComponent
+		JSON      string
+	}{
+		"Button": {
+			Button(discordgo.SuccessButton, "Yes", "vote:yes", ButtonWithEmoji(discordgo.ComponentEmoji{Name: "👍"})),
+			`{"type":2,"style":3,"label":"Yes","custom_id":"vote:yes","disabled":false,"emoji":{"name":"👍"}}`,
+		},
+		"LinkButton": {
+			LinkButton("Docs", "https://example.com", ButtonDisabled()),
+			`{"type":2,"style":5,"label":"Docs","url":"https://example.com","disabled":true,"emoji":{}}`,
+		},
+		"StringSelect": {
+			StringSelect("colour", []discordgo.SelectMenuOption{SelectOption("Red", "red")},
+				SelectWithPlaceholder("Pick one"), SelectValues(0, 1)),
+			`{"type":3,"custom_id":"colour","placeholder":"Pick one","min_values":0,"max_values":1,"disabled":false,
+				"options":[{"label":"Red","value":"red","description":"","emoji":{},"default":false}]}`,
+		},
+		"UserSelect": {
+			UserSelect("user"),
+			`{"type":5,"custom_id":"user","placeholder":"","disabled":false}`,
+		},
+		"RoleSelect": {
+			RoleSelect("role", SelectDisabled()),
+			`{"type":6,"custom_id":"role","placeholder":"","disabled":true}`,
+		},
+		"MentionableSelect": {
+			MentionableSelect("who"),
+			`{"type":7,"custom_id":"who","placeholder":"","disabled":false}`,
+		},
+		"ChannelSelect": {
+			ChannelSelect("channel", SelectChannelTypes(discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildForum)),
+			`{"type":8,"custom_id":"channel","placeholder":"","disabled":false,"channel_types":[0,15]}`,
+		},
+		"ActionRow": {
+			ActionRow(Button(discordgo.PrimaryButton, "A", "a"), Button(discordgo.DangerButton, "B", "b")),
+			`{"type":1,"components":[
+				{"type":2,"style":1,"label":"A","custom_id":"a","disabled":false,"emoji":{}},
+				{"type":2,"style":4,"label":"B","custom_id":"b","disabled":false,"emoji":{}}
+			]}`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(tc.Component)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.JSON, string(data))
+		})
+	}
+}
+
+func TestValidateComponents(t *testing.T) {
+	button := Button(discordgo.PrimaryButton, "A", "a")
+	options := make([]discordgo.SelectMenuOption, SelectMenuMaxOptions+1)
+	for name, tc := range map[string]struct {
+		Components []discordgo.MessageComponent
+		Err        string
+	}{
+		"OK": {[]discordgo.MessageComponent{ActionRow(button, button), ActionRow(UserSelect("u"))}, ""},
+		"Rows": {[]discordgo.MessageComponent{
+			ActionRow(button), ActionRow(button), ActionRow(button), ActionRow(button), ActionRow(button), ActionRow(button),
+		}, "components: 6 exceeds the limit of 5"},
+		"Buttons": {[]discordgo.MessageComponent{
+			ActionRow(button, button, button, button, button, button),
+		}, "components.0.components: 6 exceeds the limit of 5"},
+		"SelectAndButton": {[]discordgo.MessageComponent{
+			ActionRow(button), &discordgo.ActionsRow{Components: []discordgo.MessageComponent{RoleSelect("r"), button}},
+		}, "components.1.components: 2 exceeds the limit of 1"},
+		"Options": {[]discordgo.MessageComponent{
+			ActionRow(StringSelect("s", options)),
+		}, "components.0.components.0.options: 26 exceeds the limit of 25"},
+		"CustomID": {[]discordgo.MessageComponent{
+			ActionRow(button, Button(discordgo.PrimaryButton, "B", strings.Repeat("b", 101))),
+		}, "components.0.components.1.custom_id: 101 exceeds the limit of 100"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateComponents(tc.Components...)
+			if tc.Err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.IsType(t, &ComponentLimitError{}, err)
+			assert.EqualError(t, err, tc.Err)
+		})
+	}
+}
+
+func TestClientMessageComponents(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"id":"5678"}`)
+	defer srv.Close()
+	ctx := context.Background()
+	row := ActionRow(Button(discordgo.PrimaryButton, "A", "a"))
+	rowJSON := `{"type":1,"components":[{"type":2,"style":1,"label":"A","custom_id":"a","disabled":false,"emoji":{}}]}`
+
+	_, err := cl.ChannelMessageCreate(ctx, "1234", "hi", SendWithComponents(row), SendWithNonce("1", false))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"content":"hi","tts":false,"embeds":null,"nonce":"1","components":[`+rowJSON+`]}`, rec.Last().Body)
+
+	_, err = cl.ChannelMessageEdit(ctx, "1234", "5678", EditWithComponents(row), EditWithComponents(row))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"components":[`+rowJSON+`,`+rowJSON+`]}`, rec.Last().Body)
+
+	_, err = cl.ChannelMessageEdit(ctx, "1234", "5678", EditWithoutComponents())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"components":[]}`, rec.Last().Body)
+
+	n := len(rec.All())
+	rows := []discordgo.MessageComponent{row, row, row, row, row, row}
+	_, err = cl.ChannelMessageCreate(ctx, "1234", "hi", SendWithComponents(rows...))
+	assert.EqualError(t, err, "components: 6 exceeds the limit of 5")
+	_, err = cl.ChannelMessageEdit(ctx, "1234", "5678", EditWithComponents(rows...))
+	assert.EqualError(t, err, "components: 6 exceeds the limit of 5")
+	assert.Len(t, rec.All(), n, "invalid messages shouldn't be sent")
+}
@@ -7,8 +7,9 @@ import (
 type ctxKey string
 
 const (
-	ctxKeyClient   ctxKey = "client"
-	ctxKeyWSClient ctxKey = "wsclient"
+	ctxKeyClient     ctxKey = "client"
+	ctxKeyWSClient   ctxKey = "wsclient"
+	ctxKeyReqOptions ctxKey = "reqoptions"
 )
 
 // Returns the Client for a context. Returns nil if used outside of a handler function.
@@ -32,3 +33,17 @@ func GetWSClient(ctx context.Context) WSClient {
 func withWSClient(ctx context.Context, ws WSClient) context.Context {
 	return context.WithValue(ctx, ctxKeyWSClient, ws)
 }
+
+// Returns a context that applies the given ReqOptions to any requests made with it.
+// This lets you customise requests made by Client methods that don't take options, eg:
+//
+//	cl.User(WithReqOptions(ctx, WithRetryPolicy(RetryPolicy{})), "@me")
+func WithReqOptions(ctx context.Context, opts ...ReqOption) context.Context {
+	return context.WithValue(ctx, ctxKeyReqOptions, append(getReqOptions(ctx), opts...))
+}
+
+// Returns ReqOptions added to a context with WithReqOptions().
+func getReqOptions(ctx context.Context) []ReqOption {
+	opts, _ := ctx.Value(ctxKeyReqOptions).([]ReqOption)
+	return opts[:len(opts):len(opts)]
+}
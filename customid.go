@@ -0,0 +1,267 @@
+package dgo2poc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Length of the truncated HMAC-SHA256 signature in encoded custom IDs, in bytes.
+const customIDMACSize = 10
+
+// Packs state into component custom IDs, so it survives restarts without a database:
+//
+//	name:base64(version, timestamp, fields..., hmac)
+//
+// The name is used to route components to handlers; see HandleCustomID(). The state is a struct,
+// whose exported fields are encoded in order, as compactly as possible:
+//
+//	type VoteState struct {
+//		PollID string `customid:"snowflake"` // Encoded as an integer, not a string.
+//		Choice uint8
+//		Admin  bool
+//		Note   string `customid:"-"` // Not encoded.
+//	}
+//
+// Fields may be bools, ints, uints, floats or strings; strings tagged `customid:"snowflake"` must
+// hold Discord IDs. IDs are signed with a key, so users can't forge them, and must fit within
+// CustomIDMaxLength. Bump the version whenever a state struct changes, to reject old IDs.
+type CustomIDCodec struct {
+	Key     []byte
+	Version byte
+
+	// If non-zero, IDs older than this are rejected with ErrCustomIDExpired.
+	MaxAge time.Duration
+
+	now func() time.Time // For testing.
+}
+
+// Options for NewCustomIDCodec().
+type CustomIDOpt func(c *CustomIDCodec)
+
+// Set the version of encoded IDs; IDs with other versions are rejected with ErrCustomIDVersion.
+func CustomIDVersion(v byte) CustomIDOpt {
+	return CustomIDOpt(func(c *CustomIDCodec) {
+		c.Version = v
+	})
+}
+
+// Reject IDs older than the given duration with ErrCustomIDExpired.
+func CustomIDMaxAge(d time.Duration) CustomIDOpt {
+	return CustomIDOpt(func(c *CustomIDCodec) {
+		c.MaxAge = d
+	})
+}
+
+// Returns a codec that signs IDs with the given key, which should be at least 32 random bytes.
+func NewCustomIDCodec(key []byte, opts ...CustomIDOpt) *CustomIDCodec {
+	c := &CustomIDCodec{Key: key}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Encodes a state struct (or a pointer to one) into a custom ID, prefixed with name.
+func (c *CustomIDCodec) Encode(name string, v interface{}) (string, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return "", errors.Errorf("custom ID state must be a struct, not %s", rv.Type())
+	}
+	buf := []byte{c.Version}
+	buf = binary.AppendUvarint(buf, uint64(c.timeNow().Unix()-DiscordEpoch/1000))
+	buf, err := appendCustomIDFields(buf, rv)
+	if err != nil {
+		return "", errors.Wrap(err, name)
+	}
+	buf = append(buf, c.mac(name, buf)...)
+
+	id := name + ":" + base64.RawURLEncoding.EncodeToString(buf)
+	if len(id) > CustomIDMaxLength {
+		return "", errors.Errorf("%s: custom ID is %d characters long, the limit is %d", name, len(id), CustomIDMaxLength)
+	}
+	return id, nil
+}
+
+// Decodes a custom ID into a pointer to a state struct. Returns a *CustomIDError if the ID is
+// malformed, forged, from another version or expired.
+func (c *CustomIDCodec) Decode(id string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("custom ID state must be a pointer to a struct, not %s", rv.Type())
+	}
+
+	idx := strings.LastIndexByte(id, ':')
+	if idx < 0 {
+		return &CustomIDError{CustomID: id, Err: ErrCustomIDMalformed}
+	}
+	name := id[:idx]
+	buf, err := base64.RawURLEncoding.DecodeString(id[idx+1:])
+	if err != nil || len(buf) < 1+customIDMACSize {
+		return &CustomIDError{CustomID: id, Err: ErrCustomIDMalformed}
+	}
+	data, sig := buf[:len(buf)-customIDMACSize], buf[len(buf)-customIDMACSize:]
+	if !hmac.Equal(sig, c.mac(name, data)) {
+		return &CustomIDError{CustomID: id, Err: ErrCustomIDForged}
+	}
+	if data[0] != c.Version {
+		return &CustomIDError{CustomID: id, Err: ErrCustomIDVersion}
+	}
+
+	ts, n := binary.Uvarint(data[1:])
+	if n <= 0 {
+		return &CustomIDError{CustomID: id, Err: ErrCustomIDMalformed}
+	}
+	created := time.Unix(int64(ts)+DiscordEpoch/1000, 0)
+	if c.MaxAge > 0 && c.timeNow().Sub(created) > c.MaxAge {
+		return &CustomIDError{CustomID: id, Err: ErrCustomIDExpired}
+	}
+
+	rest, err := readCustomIDFields(data[1+n:], rv.Elem())
+	if err != nil || len(rest) > 0 {
+		return &CustomIDError{CustomID: id, Err: ErrCustomIDMalformed}
+	}
+	return nil
+}
+
+// Returns the signature for an encoded ID's name and data.
+func (c *CustomIDCodec) mac(name string, data []byte) []byte {
+	h := hmac.New(sha256.New, c.Key)
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write(data)
+	return h.Sum(nil)[:customIDMACSize]
+}
+
+func (c *CustomIDCodec) timeNow() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// Registers a component handler for custom IDs encoded by the codec with the given name, which
+// receives the decoded state. IDs that can't be decoded are rejected with a *CustomIDError.
+//
+//	HandleCustomID(r, codec, "vote", func(ctx context.Context, c *ComponentInteraction, state *VoteState) error {
+//		return c.Update(ctx, EditWithContent(fmt.Sprintf("Voted for %d", state.Choice)))
+//	})
+//	id, err := codec.Encode("vote", VoteState{PollID: poll.ID, Choice: 1})
+func HandleCustomID[T any](r *Router, codec *CustomIDCodec, name string, fn func(ctx context.Context, c *ComponentInteraction, state *T) error) {
+	r.HandleComponent(name+":", func(ctx context.Context, c *ComponentInteraction) error {
+		var state T
+		if err := codec.Decode(c.Data.CustomID, &state); err != nil {
+			return err
+		}
+		return fn(ctx, c, &state)
+	})
+}
+
+// Appends a struct's fields to an encoded ID.
+func appendCustomIDFields(buf []byte, rv reflect.Value) ([]byte, error) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("customid")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		fv := rv.Field(i)
+		switch f.Type.Kind() {
+		case reflect.Bool:
+			b := byte(0)
+			if fv.Bool() {
+				b = 1
+			}
+			buf = append(buf, b)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			buf = binary.AppendVarint(buf, fv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			buf = binary.AppendUvarint(buf, fv.Uint())
+		case reflect.Float32, reflect.Float64:
+			buf = binary.BigEndian.AppendUint64(buf, math.Float64bits(fv.Float()))
+		case reflect.String:
+			if tag == "snowflake" {
+				n, err := strconv.ParseUint(fv.String(), 10, 64)
+				if err != nil {
+					return nil, errors.Wrapf(err, "%s", f.Name)
+				}
+				buf = binary.AppendUvarint(buf, n)
+			} else {
+				buf = binary.AppendUvarint(buf, uint64(fv.Len()))
+				buf = append(buf, fv.String()...)
+			}
+		default:
+			return nil, errors.Errorf("%s: unsupported type: %s", f.Name, f.Type)
+		}
+	}
+	return buf, nil
+}
+
+// Reads a struct's fields from an encoded ID, returning the remaining data.
+func readCustomIDFields(buf []byte, rv reflect.Value) ([]byte, error) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("customid")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		fv := rv.Field(i)
+		switch f.Type.Kind() {
+		case reflect.Bool:
+			if len(buf) < 1 || buf[0] > 1 {
+				return nil, errors.Errorf("%s: invalid bool", f.Name)
+			}
+			fv.SetBool(buf[0] == 1)
+			buf = buf[1:]
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			v, n := binary.Varint(buf)
+			if n <= 0 || fv.OverflowInt(v) {
+				return nil, errors.Errorf("%s: invalid int", f.Name)
+			}
+			fv.SetInt(v)
+			buf = buf[n:]
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			v, n := binary.Uvarint(buf)
+			if n <= 0 || fv.OverflowUint(v) {
+				return nil, errors.Errorf("%s: invalid uint", f.Name)
+			}
+			fv.SetUint(v)
+			buf = buf[n:]
+		case reflect.Float32, reflect.Float64:
+			if len(buf) < 8 {
+				return nil, errors.Errorf("%s: invalid float", f.Name)
+			}
+			fv.SetFloat(math.Float64frombits(binary.BigEndian.Uint64(buf)))
+			buf = buf[8:]
+		case reflect.String:
+			v, n := binary.Uvarint(buf)
+			if n <= 0 {
+				return nil, errors.Errorf("%s: invalid string", f.Name)
+			}
+			buf = buf[n:]
+			if tag == "snowflake" {
+				fv.SetString(strconv.FormatUint(v, 10))
+			} else {
+				if uint64(len(buf)) < v {
+					return nil, errors.Errorf("%s: invalid string", f.Name)
+				}
+				fv.SetString(string(buf[:v]))
+				buf = buf[v:]
+			}
+		default:
+			return nil, errors.Errorf("%s: unsupported type: %s", f.Name, f.Type)
+		}
+	}
+	return buf, nil
+}
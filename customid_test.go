@@ -0,0 +1,136 @@
+package dgo2poc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCustomIDState struct {
+	UserID string `customid:"snowflake"`
+	Page   int
+	Action uint8
+	Admin  bool
+	Score  float64
+	Label  string
+	Note   string `customid:"-"`
+	hidden int
+}
+
+func TestCustomIDCodec(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	codec := NewCustomIDCodec(key, CustomIDVersion(2))
+	state := testCustomIDState{
+		UserID: "1234567890123456789", Page: -3, Action: 7, Admin: true, Score: 0.5, Label: "hi",
+		Note: "not encoded", hidden: 1,
+	}
+
+	id, err := codec.Encode("vote", state)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(id, "vote:"), id)
+	assert.LessOrEqual(t, len(id), CustomIDMaxLength)
+
+	var got testCustomIDState
+	require.NoError(t, codec.Decode(id, &got))
+	assert.Equal(t, testCustomIDState{
+		UserID: "1234567890123456789", Page: -3, Action: 7, Admin: true, Score: 0.5, Label: "hi",
+	}, got)
+
+	t.Run("Pointer", func(t *testing.T) {
+		id2, err := codec.Encode("vote", &state)
+		require.NoError(t, err)
+		var got testCustomIDState
+		require.NoError(t, codec.Decode(id2, &got))
+		assert.Equal(t, state.Label, got.Label)
+	})
+
+	t.Run("TooLong", func(t *testing.T) {
+		_, err := codec.Encode("vote", testCustomIDState{UserID: "1", Label: strings.Repeat("a", 100)})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "the limit is 100")
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		_, err := codec.Encode("vote", struct{ X []int }{})
+		assert.EqualError(t, err, "vote: X: unsupported type: []int")
+		_, err = codec.Encode("vote", 1)
+		assert.EqualError(t, err, "custom ID state must be a struct, not int")
+		_, err = codec.Encode("vote", testCustomIDState{UserID: "me"})
+		assert.Error(t, err)
+	})
+
+	for name, tc := range map[string]struct {
+		Codec *CustomIDCodec
+		ID    string
+		Err   error
+	}{
+		"Forged":      {codec, strings.Replace(id, "vote:", "voTe:", 1), ErrCustomIDForged},
+		"WrongKey":    {NewCustomIDCodec([]byte("nope"), CustomIDVersion(2)), id, ErrCustomIDForged},
+		"Tampered":    {codec, id[:len(id)-16] + "AAAA" + id[len(id)-12:], ErrCustomIDForged},
+		"Version":     {NewCustomIDCodec(key, CustomIDVersion(3)), id, ErrCustomIDVersion},
+		"NoName":      {codec, "abcd", ErrCustomIDMalformed},
+		"NotBase64":   {codec, "vote:!!!", ErrCustomIDMalformed},
+		"Short":       {codec, "vote:AAAA", ErrCustomIDMalformed},
+		"WrongFields": {codec, mustEncodeCustomID(t, codec, "vote", struct{ A, B, C, D, E, F, G, H int }{}), ErrCustomIDMalformed},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var got testCustomIDState
+			err := tc.Codec.Decode(tc.ID, &got)
+			var idErr *CustomIDError
+			require.True(t, errors.As(err, &idErr), "%v", err)
+			assert.Equal(t, tc.ID, idErr.CustomID)
+			assert.True(t, errors.Is(err, tc.Err), "%v", err)
+		})
+	}
+
+	t.Run("Expired", func(t *testing.T) {
+		now := time.Now()
+		codec := NewCustomIDCodec(key, CustomIDMaxAge(time.Hour))
+		codec.now = func() time.Time { return now }
+		id, err := codec.Encode("vote", state)
+		require.NoError(t, err)
+
+		codec.now = func() time.Time { return now.Add(59 * time.Minute) }
+		var got testCustomIDState
+		require.NoError(t, codec.Decode(id, &got))
+
+		codec.now = func() time.Time { return now.Add(61 * time.Minute) }
+		err = codec.Decode(id, &got)
+		assert.True(t, errors.Is(err, ErrCustomIDExpired), "%v", err)
+		assert.EqualError(t, err, `custom ID "`+id+`": expired`)
+	})
+}
+
+func mustEncodeCustomID(t *testing.T, codec *CustomIDCodec, name string, v interface{}) string {
+	id, err := codec.Encode(name, v)
+	require.NoError(t, err)
+	return id
+}
+
+func TestHandleCustomID(t *testing.T) {
+	codec := NewCustomIDCodec([]byte("key"))
+	r := NewRouter()
+	var got *testCustomIDState
+	HandleCustomID(r, codec, "vote", func(ctx context.Context, c *ComponentInteraction, state *testCustomIDState) error {
+		got = state
+		return nil
+	})
+
+	id := mustEncodeCustomID(t, codec, "vote", testCustomIDState{UserID: "1234", Page: 2})
+	i := newTestInteractionWithData(t, discordgo.InteractionMessageComponent, time.Now(), `{"custom_id":"`+id+`","component_type":2}`)
+	require.NoError(t, r.Dispatch(context.Background(), i))
+	require.NotNil(t, got)
+	assert.Equal(t, "1234", got.UserID)
+	assert.Equal(t, 2, got.Page)
+
+	forged := mustEncodeCustomID(t, NewCustomIDCodec([]byte("other key")), "vote", testCustomIDState{UserID: "1"})
+	i = newTestInteractionWithData(t, discordgo.InteractionMessageComponent, time.Now(), `{"custom_id":"`+forged+`","component_type":2}`)
+	err := r.Dispatch(context.Background(), i)
+	assert.True(t, errors.Is(err, ErrCustomIDForged), "%v", err)
+}
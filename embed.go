@@ -0,0 +1,197 @@
+package dgo2poc
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pkg/errors"
+)
+
+// Embed limits, in characters. The total counts titles, descriptions, field names and values, footer
+// texts and author names, across all embeds in a message.
+const (
+	EmbedTitleMaxLength       = 256
+	EmbedDescriptionMaxLength = 4096
+	EmbedMaxFields            = 25
+	EmbedFieldNameMaxLength   = 256
+	EmbedFieldValueMaxLength  = 1024
+	EmbedFooterMaxLength      = 2048
+	EmbedAuthorNameMaxLength  = 256
+	EmbedTotalMaxLength       = 6000
+	MessageMaxEmbeds          = 10
+)
+
+// Builds an embed, eg:
+//
+//	embed := NewEmbed().
+//		Title("Weather").
+//		Color(0x3498db).
+//		Field("Temperature", "21°C", true).
+//		ImageFromAttachment("chart.png").
+//		Build()
+//	cl.ChannelMessageCreate(ctx, channel, "", SendWithEmbed(embed), SendWithFile("chart.png", f))
+type EmbedBuilder struct {
+	embed discordgo.MessageEmbed
+}
+
+// Returns a new, empty embed builder.
+func NewEmbed() *EmbedBuilder {
+	return &EmbedBuilder{}
+}
+
+// Set the embed's title.
+func (b *EmbedBuilder) Title(title string) *EmbedBuilder {
+	b.embed.Title = title
+	return b
+}
+
+// Make the embed's title a link.
+func (b *EmbedBuilder) URL(url string) *EmbedBuilder {
+	b.embed.URL = url
+	return b
+}
+
+// Set the embed's description, which supports markdown.
+func (b *EmbedBuilder) Description(desc string) *EmbedBuilder {
+	b.embed.Description = desc
+	return b
+}
+
+// Set the colour of the embed's left border, as 0xRRGGBB.
+func (b *EmbedBuilder) Color(rgb int) *EmbedBuilder {
+	b.embed.Color = rgb
+	return b
+}
+
+// Show a timestamp in the embed's footer, in the viewer's timezone.
+func (b *EmbedBuilder) Timestamp(t time.Time) *EmbedBuilder {
+	b.embed.Timestamp = t.Format(time.RFC3339)
+	return b
+}
+
+// Add a field. Up to 3 inline fields are shown side by side.
+func (b *EmbedBuilder) Field(name, value string, inline bool) *EmbedBuilder {
+	b.embed.Fields = append(b.embed.Fields, &discordgo.MessageEmbedField{Name: name, Value: value, Inline: inline})
+	return b
+}
+
+// Set the embed's author, shown above the title. The URL and icon URL may be empty.
+func (b *EmbedBuilder) Author(name, url, iconURL string) *EmbedBuilder {
+	b.embed.Author = &discordgo.MessageEmbedAuthor{Name: name, URL: url, IconURL: iconURL}
+	return b
+}
+
+// Set the embed's footer. The icon URL may be empty.
+func (b *EmbedBuilder) Footer(text, iconURL string) *EmbedBuilder {
+	b.embed.Footer = &discordgo.MessageEmbedFooter{Text: text, IconURL: iconURL}
+	return b
+}
+
+// Show a large image at the bottom of the embed.
+func (b *EmbedBuilder) Image(url string) *EmbedBuilder {
+	b.embed.Image = &discordgo.MessageEmbedImage{URL: url}
+	return b
+}
+
+// Show a small image in the embed's top right corner.
+func (b *EmbedBuilder) Thumbnail(url string) *EmbedBuilder {
+	b.embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: url}
+	return b
+}
+
+// Show a file attached to the same message as the embed's image; see SendWithFile().
+func (b *EmbedBuilder) ImageFromAttachment(filename string) *EmbedBuilder {
+	return b.Image("attachment://" + filename)
+}
+
+// Show a file attached to the same message as the embed's thumbnail; see SendWithFile().
+func (b *EmbedBuilder) ThumbnailFromAttachment(filename string) *EmbedBuilder {
+	return b.Thumbnail("attachment://" + filename)
+}
+
+// Checks the embed against Discord's limits; see ValidateEmbeds(). Its total length is reported
+// as "total".
+func (b *EmbedBuilder) Validate() error {
+	var total int
+	if err := validateEmbed("", &b.embed, &total); err != nil {
+		return err
+	}
+	if total > EmbedTotalMaxLength {
+		return &EmbedLimitError{Field: "total", Length: total, Max: EmbedTotalMaxLength}
+	}
+	return nil
+}
+
+// Returns the built embed. The builder can be reused; changes to it don't affect returned embeds.
+func (b *EmbedBuilder) Build() *discordgo.MessageEmbed {
+	embed := b.embed
+	embed.Fields = append([]*discordgo.MessageEmbedField(nil), b.embed.Fields...)
+	return &embed
+}
+
+// Checks a message's embeds against Discord's limits. Returns an *EmbedLimitError for the first
+// limit that's exceeded, naming it like the API does, eg. "embeds.0.fields.3.value"; or "embeds"
+// for both the number of embeds and their total length. Nil embeds and fields are also errors.
+func ValidateEmbeds(embeds ...*discordgo.MessageEmbed) error {
+	if len(embeds) > MessageMaxEmbeds {
+		return &EmbedLimitError{Field: "embeds", Length: len(embeds), Max: MessageMaxEmbeds}
+	}
+	var total int
+	for i, embed := range embeds {
+		if embed == nil {
+			return errors.Errorf("embeds.%d: embed is nil", i)
+		}
+		if err := validateEmbed(fmt.Sprintf("embeds.%d.", i), embed, &total); err != nil {
+			return err
+		}
+	}
+	if total > EmbedTotalMaxLength {
+		return &EmbedLimitError{Field: "embeds", Length: total, Max: EmbedTotalMaxLength}
+	}
+	return nil
+}
+
+// Checks an embed's limits, adding its length to total.
+func validateEmbed(prefix string, embed *discordgo.MessageEmbed, total *int) error {
+	check := func(field, s string, max int) error {
+		n := utf8.RuneCountInString(s)
+		*total += n
+		if n > max {
+			return &EmbedLimitError{Field: prefix + field, Length: n, Max: max}
+		}
+		return nil
+	}
+	if err := check("title", embed.Title, EmbedTitleMaxLength); err != nil {
+		return err
+	}
+	if err := check("description", embed.Description, EmbedDescriptionMaxLength); err != nil {
+		return err
+	}
+	if len(embed.Fields) > EmbedMaxFields {
+		return &EmbedLimitError{Field: prefix + "fields", Length: len(embed.Fields), Max: EmbedMaxFields}
+	}
+	for i, f := range embed.Fields {
+		if f == nil {
+			return errors.Errorf("%sfields.%d: field is nil", prefix, i)
+		}
+		if err := check(fmt.Sprintf("fields.%d.name", i), f.Name, EmbedFieldNameMaxLength); err != nil {
+			return err
+		}
+		if err := check(fmt.Sprintf("fields.%d.value", i), f.Value, EmbedFieldValueMaxLength); err != nil {
+			return err
+		}
+	}
+	if embed.Footer != nil {
+		if err := check("footer.text", embed.Footer.Text, EmbedFooterMaxLength); err != nil {
+			return err
+		}
+	}
+	if embed.Author != nil {
+		if err := check("author.name", embed.Author.Name, EmbedAuthorNameMaxLength); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package dgo2poc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbedBuilder(t *testing.T) {
+	ts := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	b := NewEmbed().
+		Title("Weather").
+		URL("https://example.com").
+		Description("Sunny").
+		Color(0x3498db).
+		Timestamp(ts).
+		Field("Temperature", "21°C", true).
+		Field("Wind", "None", false).
+		Author("Bot", "", "https://example.com/bot.png").
+		Footer("Powered by cats", "").
+		ImageFromAttachment("chart.png").
+		ThumbnailFromAttachment("icon.png")
+	require.NoError(t, b.Validate())
+
+	embed := b.Build()
+	assert.Equal(t, &discordgo.MessageEmbed{
+		Title:       "Weather",
+		URL:         "https://example.com",
+		Description: "Sunny",
+		Color:       0x3498db,
+		Timestamp:   "2023-01-02T03:04:05Z",
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Temperature", Value: "21°C", Inline: true},
+			{Name: "Wind", Value: "None"},
+		},
+		Author:    &discordgo.MessageEmbedAuthor{Name: "Bot", IconURL: "https://example.com/bot.png"},
+		Footer:    &discordgo.MessageEmbedFooter{Text: "Powered by cats"},
+		Image:     &discordgo.MessageEmbedImage{URL: "attachment://chart.png"},
+		Thumbnail: &discordgo.MessageEmbedThumbnail{URL: "attachment://icon.png"},
+	}, embed)
+
+	b.Field("More", "stuff", false)
+	assert.Len(t, embed.Fields, 2, "builder changes shouldn't affect built embeds")
+}
+
+func TestValidateEmbeds(t *testing.T) {
+	fields := func(n int) *EmbedBuilder {
+		b := NewEmbed()
+		for i := 0; i < n; i++ {
+			b.Field("a", "b", false)
+		}
+		return b
+	}
+	for name, tc := range map[string]struct {
+		Embed *EmbedBuilder
+		Err   string
+	}{
+		"Title":        {NewEmbed().Title(strings.Repeat("a", 257)), "title: 257 exceeds the limit of 256"},
+		"TitleRunes":   {NewEmbed().Title(strings.Repeat("é", 256)), ""},
+		"Description":  {NewEmbed().Description(strings.Repeat("a", 4097)), "description: 4097 exceeds the limit of 4096"},
+		"Fields":       {fields(26), "fields: 26 exceeds the limit of 25"},
+		"FieldName":    {NewEmbed().Field("a", "b", false).Field(strings.Repeat("a", 257), "b", false), "fields.1.name: 257 exceeds the limit of 256"},
+		"FieldValue":   {NewEmbed().Field("a", strings.Repeat("b", 1025), false), "fields.0.value: 1025 exceeds the limit of 1024"},
+		"Footer":       {NewEmbed().Footer(strings.Repeat("a", 2049), ""), "footer.text: 2049 exceeds the limit of 2048"},
+		"Author":       {NewEmbed().Author(strings.Repeat("a", 257), "", ""), "author.name: 257 exceeds the limit of 256"},
+		"Total":        {NewEmbed().Description(strings.Repeat("a", 4096)).Footer(strings.Repeat("a", 2000), ""), "total: 6096 exceeds the limit of 6000"},
+		"TotalAtLimit": {NewEmbed().Description(strings.Repeat("a", 4096)).Footer(strings.Repeat("a", 1904), ""), ""},
+		"OK":           {fields(25), ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.Embed.Validate()
+			if tc.Err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var lerr *EmbedLimitError
+			require.True(t, errors.As(err, &lerr), "%v", err)
+			assert.EqualError(t, err, tc.Err)
+		})
+	}
+
+	t.Run("Message", func(t *testing.T) {
+		small := NewEmbed().Title("a").Build()
+		embed := NewEmbed().Description(strings.Repeat("a", 1000)).Build()
+		assert.NoError(t, ValidateEmbeds(embed, embed, embed, embed, embed, embed))
+		assert.EqualError(t, ValidateEmbeds(embed, embed, embed, embed, embed, embed, embed),
+			"embeds: 7000 exceeds the limit of 6000")
+		assert.EqualError(t, ValidateEmbeds(small, nil), "embeds.1: embed is nil")
+		assert.EqualError(t, ValidateEmbeds(small, &discordgo.MessageEmbed{Fields: []*discordgo.MessageEmbedField{nil}}),
+			"embeds.1.fields.0: field is nil")
+
+		// The total is reported for the whole message, not just up to the embed that crossed it.
+		var lerr *EmbedLimitError
+		require.True(t, errors.As(ValidateEmbeds(embed, embed, embed, embed, embed, embed, embed, embed), &lerr))
+		assert.Equal(t, 8000, lerr.Length)
+
+		many := make([]*discordgo.MessageEmbed, 11)
+		for i := range many {
+			many[i] = small
+		}
+		assert.NoError(t, ValidateEmbeds(many[:10]...))
+		assert.EqualError(t, ValidateEmbeds(many...), "embeds: 11 exceeds the limit of 10")
+		assert.EqualError(t, ValidateEmbeds(small, NewEmbed().Title(strings.Repeat("a", 300)).Build()),
+			"embeds.1.title: 300 exceeds the limit of 256")
+	})
+}
+
+func TestClientMessageEmbeds(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"id":"5678"}`)
+	defer srv.Close()
+	ctx := context.Background()
+
+	_, err := cl.ChannelMessageCreate(ctx, "1234", "", SendWithNonce("1", false),
+		SendWithEmbed(NewEmbed().Title("a").Build()),
+		SendWithEmbeds(NewEmbed().Title("b").Build(), NewEmbed().Title("c").Build()),
+	)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"tts":false,"nonce":"1","components":null,
+		"embeds":[{"title":"a"},{"title":"b"},{"title":"c"}]}`, rec.Last().Body)
+
+	n := len(rec.All())
+	tooLong := NewEmbed().Title(strings.Repeat("a", 300)).Build()
+	_, err = cl.ChannelMessageCreate(ctx, "1234", "", SendWithEmbed(tooLong))
+	assert.EqualError(t, err, "embeds.0.title: 300 exceeds the limit of 256")
+	_, err = cl.ChannelMessageEdit(ctx, "1234", "5678", EditWithEmbed(tooLong))
+	assert.EqualError(t, err, "embeds.0.title: 300 exceeds the limit of 256")
+	i := newTestInteraction(discordgo.InteractionApplicationCommand, time.Now())
+	err = cl.InteractionRespond(ctx, i, "", SendWithEmbed(tooLong))
+	assert.EqualError(t, err, "embeds.0.title: 300 exceeds the limit of 256")
+	assert.Len(t, rec.All(), n, "invalid messages shouldn't be sent")
+}
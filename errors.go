@@ -1,11 +1,320 @@
 package dgo2poc
 
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Sentinel errors for common HTTP statuses; use with errors.Is(), eg. errors.Is(err, ErrNotFound).
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrRateLimited  = errors.New("rate limited")
+)
+
+// Sentinel errors for expired interactions; see InteractionExpiredError.
+var (
+	ErrInteractionTimeout      = errors.New("interaction wasn't responded to in time")
+	ErrInteractionTokenExpired = errors.New("interaction token has expired")
+)
+
+// Sentinel errors for custom IDs that can't be decoded; see CustomIDError.
+var (
+	ErrCustomIDMalformed = errors.New("malformed")
+	ErrCustomIDForged    = errors.New("signature mismatch")
+	ErrCustomIDVersion   = errors.New("unknown version")
+	ErrCustomIDExpired   = errors.New("expired")
+)
+
+// Common API error codes; see APIError.Code and ErrorCode().
+const (
+	APIErrorUnknownChannel         = 10003
+	APIErrorUnknownGuild           = 10004
+	APIErrorUnknownMember          = 10007
+	APIErrorUnknownMessage         = 10008
+	APIErrorUnknownRole            = 10011
+	APIErrorUnknownUser            = 10013
+	APIErrorUnknownWebhook         = 10015
+	APIErrorUnknownInteraction     = 10062
+	APIErrorMissingAccess          = 50001
+	APIErrorCannotSendEmptyMessage = 50006
+	APIErrorMissingPermissions     = 50013
+	APIErrorInvalidWebhookToken    = 50027
+	APIErrorInvalidFormBody        = 50035
+)
+
+// Returned by Client.Request() for any response with an error status.
+type HTTPError struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	// Error returned by the API, nil if the body couldn't be parsed as one.
+	APIError *APIError
+}
+
+// Creates an HTTPError from a response, parsing the body as an APIError if possible.
+func newHTTPError(resp *http.Response, data []byte) *HTTPError {
+	err := &HTTPError{StatusCode: resp.StatusCode, Header: resp.Header, Body: data}
+	var apiErr APIError
+	if json.Unmarshal(data, &apiErr) == nil {
+		err.APIError = &apiErr
+	}
+	return err
+}
+
+func (e *HTTPError) Error() string {
+	if e.APIError != nil {
+		return fmt.Sprintf("%d: %s", e.StatusCode, e.APIError)
+	}
+	return fmt.Sprintf("%d: %s", e.StatusCode, string(e.Body))
+}
+
+func (e *HTTPError) Unwrap() error {
+	if e.APIError == nil {
+		return nil
+	}
+	return e.APIError
+}
+
+// Matches the ErrUnauthorized, ErrForbidden, ErrNotFound and ErrRateLimited sentinels.
+func (e *HTTPError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
 // Wraps an error from the API.
 type APIError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
+
+	// Field-level validation errors, flattened from the nested "errors" object, if any.
+	Errors []FieldError `json:"-"`
+}
+
+func (e *APIError) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Errors  json.RawMessage `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	e.Code = raw.Code
+	e.Message = raw.Message
+	e.Errors = nil
+	if len(raw.Errors) > 0 {
+		errs, err := parseFieldErrors("", raw.Errors)
+		if err != nil {
+			return err
+		}
+		e.Errors = errs
+	}
+	return nil
 }
 
 func (e APIError) Error() string {
-	return e.Message
+	if len(e.Errors) == 0 {
+		return e.Message
+	}
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+	return e.Message + " (" + strings.Join(msgs, "; ") + ")"
+}
+
+// A field-level validation error, eg. for a too long embed title.
+type FieldError struct {
+	Field   string `json:"-"`       // Path to the field, eg. "embeds.0.title".
+	Code    string `json:"code"`    // Error code, eg. "BASE_TYPE_MAX_LENGTH".
+	Message string `json:"message"` // Human-readable message.
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Flattens a nested "errors" object, where each level may have a list of "_errors".
+func parseFieldErrors(path string, data json.RawMessage) ([]FieldError, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+
+	var errs []FieldError
+	if raw, ok := obj["_errors"]; ok {
+		if err := json.Unmarshal(raw, &errs); err != nil {
+			return nil, err
+		}
+		for i := range errs {
+			errs[i].Field = path
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		if key != "_errors" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		subpath := key
+		if path != "" {
+			subpath = path + "." + key
+		}
+		suberrs, err := parseFieldErrors(subpath, obj[key])
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, suberrs...)
+	}
+	return errs, nil
+}
+
+// Returns the API error code for an error, or 0 if it doesn't wrap an APIError.
+func ErrorCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return 0
+}
+
+// Returned when a request is rate limited (429), and it couldn't or wasn't allowed to be retried.
+type RateLimitError struct {
+	*HTTPError
+
+	RetryAfter time.Duration // How long to wait before retrying.
+	Global     bool          // Whether this is a global rate limit.
+	Scope      string        // "user", "global" or "shared"; from X-RateLimit-Scope.
+}
+
+// Creates a RateLimitError from the HTTPError for a 429 response.
+func newRateLimitError(httpErr *HTTPError) *RateLimitError {
+	var body struct {
+		RetryAfter float64 `json:"retry_after"`
+		Global     bool    `json:"global"`
+	}
+	_ = json.Unmarshal(httpErr.Body, &body)
+
+	err := &RateLimitError{
+		HTTPError:  httpErr,
+		RetryAfter: time.Duration(body.RetryAfter * float64(time.Second)),
+		Global:     body.Global || httpErr.Header.Get("X-RateLimit-Global") == "true",
+		Scope:      httpErr.Header.Get("X-RateLimit-Scope"),
+	}
+	if retryAfter, perr := parseSeconds(httpErr.Header.Get("Retry-After")); perr == nil && err.RetryAfter == 0 {
+		err.RetryAfter = retryAfter
+	}
+	return err
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s (retry after %s)", e.HTTPError, e.RetryAfter)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.HTTPError
+}
+
+// Returned when responding to an interaction too late: either the initial response was sent after
+// InteractionResponseTimeout, or a follow-up after InteractionTokenLifetime.
+// Use errors.Is() with ErrInteractionTimeout or ErrInteractionTokenExpired to tell them apart.
+type InteractionExpiredError struct {
+	*HTTPError
+
+	Err error         // ErrInteractionTimeout or ErrInteractionTokenExpired.
+	Age time.Duration // How long ago the interaction was created, according to the local clock.
+}
+
+func (e *InteractionExpiredError) Error() string {
+	return fmt.Sprintf("%s after %s: %s", e.Err, e.Age.Round(time.Millisecond), e.HTTPError)
+}
+
+func (e *InteractionExpiredError) Unwrap() error {
+	return e.HTTPError
+}
+
+// Matches Err, in addition to anything the HTTPError matches.
+func (e *InteractionExpiredError) Is(target error) bool {
+	return target == e.Err
+}
+
+// Returned by CustomIDCodec.Decode() for custom IDs that can't be decoded.
+type CustomIDError struct {
+	CustomID string
+	Err      error // ErrCustomIDMalformed, ErrCustomIDForged, ErrCustomIDVersion or ErrCustomIDExpired.
+}
+
+func (e *CustomIDError) Error() string {
+	return fmt.Sprintf("custom ID %q: %s", e.CustomID, e.Err)
+}
+
+func (e *CustomIDError) Unwrap() error {
+	return e.Err
+}
+
+// Returned for modal submissions that fail validation; see HandleModal().
+type ModalValidationError struct {
+	Errors []FieldError // Field is the text input's custom ID.
+}
+
+func (e *ModalValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+	return "invalid modal submission (" + strings.Join(msgs, "; ") + ")"
+}
+
+// Returned for message content longer than MessageMaxLength; such messages can be sent with
+// Client.ChannelMessageCreateSplit() instead.
+type ContentLengthError struct {
+	Length int // Length in characters.
+	Max    int
+}
+
+func (e *ContentLengthError) Error() string {
+	return fmt.Sprintf("content: %d exceeds the limit of %d", e.Length, e.Max)
+}
+
+// Returned for components that exceed one of Discord's limits; see ValidateComponents().
+type ComponentLimitError struct {
+	Field  string // Path to the field, eg. "components.0.components" or "components.1.components.0.options".
+	Length int    // Number of rows, components or options, or length of a custom ID in characters.
+	Max    int
+}
+
+func (e *ComponentLimitError) Error() string {
+	return fmt.Sprintf("%s: %d exceeds the limit of %d", e.Field, e.Length, e.Max)
+}
+
+// Returned for embeds that exceed one of Discord's limits; see ValidateEmbeds().
+type EmbedLimitError struct {
+	Field  string // Path to the field, eg. "embeds.0.title", "embeds" or "total"; see ValidateEmbeds().
+	Length int    // Length in characters, or number of fields or embeds.
+	Max    int
+}
+
+func (e *EmbedLimitError) Error() string {
+	return fmt.Sprintf("%s: %d exceeds the limit of %d", e.Field, e.Length, e.Max)
 }
@@ -0,0 +1,82 @@
+package dgo2poc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIErrorUnmarshal(t *testing.T) {
+	var apiErr APIError
+	require.NoError(t, apiErr.UnmarshalJSON([]byte(`{
+		"code": 50035,
+		"message": "Invalid Form Body",
+		"errors": {
+			"content": {"_errors": [{"code": "BASE_TYPE_MAX_LENGTH", "message": "Must be 2000 or fewer in length."}]},
+			"embeds": {"0": {"title": {"_errors": [{"code": "BASE_TYPE_REQUIRED", "message": "This field is required"}]}}}
+		}
+	}`)))
+	assert.Equal(t, APIError{
+		Code:    APIErrorInvalidFormBody,
+		Message: "Invalid Form Body",
+		Errors: []FieldError{
+			{Field: "content", Code: "BASE_TYPE_MAX_LENGTH", Message: "Must be 2000 or fewer in length."},
+			{Field: "embeds.0.title", Code: "BASE_TYPE_REQUIRED", Message: "This field is required"},
+		},
+	}, apiErr)
+	assert.EqualError(t, apiErr, "Invalid Form Body (content: Must be 2000 or fewer in length.; embeds.0.title: This field is required)")
+}
+
+func TestHTTPError(t *testing.T) {
+	testdata := map[int]error{
+		401: ErrUnauthorized,
+		403: ErrForbidden,
+		404: ErrNotFound,
+		429: ErrRateLimited,
+	}
+	for status, sentinel := range testdata {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("X-Test", "hi")
+				rw.WriteHeader(status)
+				_, _ = rw.Write([]byte(`{"code":1234,"message":"everything is broken"}`))
+			}))
+			defer srv.Close()
+			cl := NewClient(BotToken("hi"), WithRateLimitRetries(0))
+			_, err := cl.Request(context.Background(), "GET", srv.URL, nil)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, sentinel))
+			for _, other := range testdata {
+				if other != sentinel {
+					assert.False(t, errors.Is(err, other))
+				}
+			}
+			assert.Equal(t, 1234, ErrorCode(err))
+
+			var httpErr *HTTPError
+			require.True(t, errors.As(err, &httpErr))
+			assert.Equal(t, status, httpErr.StatusCode)
+			assert.Equal(t, "hi", httpErr.Header.Get("X-Test"))
+			assert.Equal(t, `{"code":1234,"message":"everything is broken"}`, string(httpErr.Body))
+		})
+	}
+
+	t.Run("Plaintext", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(500)
+			_, _ = rw.Write([]byte(`aaaa`))
+		}))
+		defer srv.Close()
+		cl := NewClient(BotToken("hi"))
+		_, err := cl.Request(context.Background(), "GET", srv.URL, nil)
+		var httpErr *HTTPError
+		require.True(t, errors.As(err, &httpErr))
+		assert.Nil(t, httpErr.APIError)
+		assert.Equal(t, 0, ErrorCode(err))
+	})
+}
@@ -0,0 +1,305 @@
+package dgo2poc
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Parameters for editing a guild; see Client.GuildEdit(). Nil fields are left unchanged.
+type GuildParams struct {
+	Name                        string                                `json:"name,omitempty"`
+	VerificationLevel           *discordgo.VerificationLevel          `json:"verification_level,omitempty"`
+	DefaultMessageNotifications *discordgo.MessageNotifications       `json:"default_message_notifications,omitempty"`
+	ExplicitContentFilter       *discordgo.ExplicitContentFilterLevel `json:"explicit_content_filter,omitempty"`
+	AFKTimeout                  *int                                  `json:"afk_timeout,omitempty"`
+	SystemChannelFlags          *discordgo.SystemChannelFlag          `json:"system_channel_flags,omitempty"`
+	PreferredLocale             *discordgo.Locale                     `json:"preferred_locale,omitempty"`
+	OwnerID                     string                                `json:"owner_id,omitempty"`
+
+	// Nullable fields: set to "" to clear them.
+	Description            *string `json:"-"`
+	Icon                   *string `json:"-"`
+	Banner                 *string `json:"-"`
+	Splash                 *string `json:"-"`
+	AFKChannelID           *string `json:"-"`
+	SystemChannelID        *string `json:"-"`
+	RulesChannelID         *string `json:"-"`
+	PublicUpdatesChannelID *string `json:"-"`
+}
+
+func (p GuildParams) MarshalJSON() ([]byte, error) {
+	type guildParams GuildParams
+	return json.Marshal(struct {
+		guildParams
+		Description            json.RawMessage `json:"description,omitempty"`
+		Icon                   json.RawMessage `json:"icon,omitempty"`
+		Banner                 json.RawMessage `json:"banner,omitempty"`
+		Splash                 json.RawMessage `json:"splash,omitempty"`
+		AFKChannelID           json.RawMessage `json:"afk_channel_id,omitempty"`
+		SystemChannelID        json.RawMessage `json:"system_channel_id,omitempty"`
+		RulesChannelID         json.RawMessage `json:"rules_channel_id,omitempty"`
+		PublicUpdatesChannelID json.RawMessage `json:"public_updates_channel_id,omitempty"`
+	}{
+		guildParams(p),
+		nullString(p.Description), nullString(p.Icon), nullString(p.Banner), nullString(p.Splash),
+		nullString(p.AFKChannelID), nullString(p.SystemChannelID),
+		nullString(p.RulesChannelID), nullString(p.PublicUpdatesChannelID),
+	})
+}
+
+// Options for Client.GuildEdit().
+type GuildOpt func(params *GuildParams)
+
+// Rename a guild.
+func GuildWithName(name string) GuildOpt {
+	return GuildOpt(func(params *GuildParams) {
+		params.Name = name
+	})
+}
+
+// Set a guild's description. Set to "" to remove it.
+func GuildWithDescription(desc string) GuildOpt {
+	return GuildOpt(func(params *GuildParams) {
+		params.Description = &desc
+	})
+}
+
+// Set a guild's icon, as a data URI, eg. "data:image/png;base64,...". Set to "" to remove it.
+func GuildWithIcon(icon string) GuildOpt {
+	return GuildOpt(func(params *GuildParams) {
+		params.Icon = &icon
+	})
+}
+
+// Set a guild's banner, as a data URI. Set to "" to remove it.
+func GuildWithBanner(banner string) GuildOpt {
+	return GuildOpt(func(params *GuildParams) {
+		params.Banner = &banner
+	})
+}
+
+// Set a guild's invite splash, as a data URI. Set to "" to remove it.
+func GuildWithSplash(splash string) GuildOpt {
+	return GuildOpt(func(params *GuildParams) {
+		params.Splash = &splash
+	})
+}
+
+// Set the verification level required to chat in a guild.
+func GuildWithVerificationLevel(level discordgo.VerificationLevel) GuildOpt {
+	return GuildOpt(func(params *GuildParams) {
+		params.VerificationLevel = &level
+	})
+}
+
+// Set the default notification level for a guild.
+func GuildWithDefaultMessageNotifications(level discordgo.MessageNotifications) GuildOpt {
+	return GuildOpt(func(params *GuildParams) {
+		params.DefaultMessageNotifications = &level
+	})
+}
+
+// Set a guild's explicit content filter level.
+func GuildWithExplicitContentFilter(level discordgo.ExplicitContentFilterLevel) GuildOpt {
+	return GuildOpt(func(params *GuildParams) {
+		params.ExplicitContentFilter = &level
+	})
+}
+
+// Set a guild's AFK channel, and how long (in seconds) before members are moved there.
+func GuildWithAFKChannel(channel string, timeout int) GuildOpt {
+	return GuildOpt(func(params *GuildParams) {
+		params.AFKChannelID = &channel
+		params.AFKTimeout = &timeout
+	})
+}
+
+// Remove a guild's AFK channel.
+func GuildWithoutAFKChannel() GuildOpt {
+	return GuildOpt(func(params *GuildParams) {
+		params.AFKChannelID = new(string)
+	})
+}
+
+// Set the channel system messages, eg. welcome messages, are posted in, and which are suppressed.
+func GuildWithSystemChannel(channel string, flags discordgo.SystemChannelFlag) GuildOpt {
+	return GuildOpt(func(params *GuildParams) {
+		params.SystemChannelID = &channel
+		params.SystemChannelFlags = &flags
+	})
+}
+
+// Remove a guild's system channel, so system messages aren't posted.
+func GuildWithoutSystemChannel() GuildOpt {
+	return GuildOpt(func(params *GuildParams) {
+		params.SystemChannelID = new(string)
+	})
+}
+
+// Set a community guild's rules channel.
+func GuildWithRulesChannel(channel string) GuildOpt {
+	return GuildOpt(func(params *GuildParams) {
+		params.RulesChannelID = &channel
+	})
+}
+
+// Set the channel a community guild receives notices from Discord in.
+func GuildWithPublicUpdatesChannel(channel string) GuildOpt {
+	return GuildOpt(func(params *GuildParams) {
+		params.PublicUpdatesChannelID = &channel
+	})
+}
+
+// Set a community guild's preferred locale.
+func GuildWithPreferredLocale(locale discordgo.Locale) GuildOpt {
+	return GuildOpt(func(params *GuildParams) {
+		params.PreferredLocale = &locale
+	})
+}
+
+// Transfer ownership of a guild. Only the current owner may do this.
+func GuildWithOwner(uid string) GuildOpt {
+	return GuildOpt(func(params *GuildParams) {
+		params.OwnerID = uid
+	})
+}
+
+// Options for Client.GuildRoleCreate() and Client.GuildRoleEdit().
+type RoleOpt func(params *discordgo.RoleParams)
+
+// Set a role's name.
+func RoleWithName(name string) RoleOpt {
+	return RoleOpt(func(params *discordgo.RoleParams) {
+		params.Name = name
+	})
+}
+
+// Set a role's colour, as an RGB value, eg. 0xFF0000 for red.
+func RoleWithColor(color int) RoleOpt {
+	return RoleOpt(func(params *discordgo.RoleParams) {
+		params.Color = &color
+	})
+}
+
+// Set whether a role's members are displayed separately in the member list.
+func RoleWithHoist(hoist bool) RoleOpt {
+	return RoleOpt(func(params *discordgo.RoleParams) {
+		params.Hoist = &hoist
+	})
+}
+
+// Set a role's permissions, eg. discordgo.PermissionSendMessages|discordgo.PermissionReadMessageHistory.
+func RoleWithPermissions(perms int64) RoleOpt {
+	return RoleOpt(func(params *discordgo.RoleParams) {
+		params.Permissions = &perms
+	})
+}
+
+// Set whether a role can be @mentioned by everyone.
+func RoleWithMentionable(mentionable bool) RoleOpt {
+	return RoleOpt(func(params *discordgo.RoleParams) {
+		params.Mentionable = &mentionable
+	})
+}
+
+// A channel's new position; see Client.GuildChannelsReorder().
+type ChannelPosition struct {
+	ID       string `json:"id"`
+	Position int    `json:"position"`
+
+	// Optionally move the channel to another category, and sync its permissions with it.
+	ParentID        *string `json:"parent_id,omitempty"`
+	LockPermissions *bool   `json:"lock_permissions,omitempty"`
+}
+
+// A role's new position; see Client.GuildRolesReorder().
+type RolePosition struct {
+	ID       string `json:"id"`
+	Position int    `json:"position"`
+}
+
+func (c *client) Guild(ctx context.Context, gid string) (*discordgo.Guild, error) {
+	var guild discordgo.Guild
+	return &guild, c.RequestJSON(ctx, "GET", c.BaseURL+EndpointGuild(gid)+"?with_counts=true", nil, &guild)
+}
+
+func (c *client) GuildPreview(ctx context.Context, gid string) (*discordgo.GuildPreview, error) {
+	var preview discordgo.GuildPreview
+	return &preview, c.RequestJSON(ctx, "GET", c.BaseURL+EndpointGuildPreview(gid), nil, &preview)
+}
+
+func (c *client) GuildEdit(ctx context.Context, gid string, opts ...GuildOpt) (*discordgo.Guild, error) {
+	var params GuildParams
+	for _, opt := range opts {
+		opt(&params)
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	var guild discordgo.Guild
+	return &guild, c.RequestJSON(ctx, "PATCH", c.BaseURL+EndpointGuild(gid), data, &guild)
+}
+
+func (c *client) UserGuilds(ctx context.Context) *Iterator[*discordgo.UserGuild] {
+	urlStr := c.BaseURL + EndpointUserGuilds("@me")
+	return newIterator(ctx, 200, pageSnowflakes(c, urlStr, nil, "after", "", func(g *discordgo.UserGuild) string {
+		return g.ID
+	}))
+}
+
+func (c *client) GuildChannels(ctx context.Context, gid string) ([]*discordgo.Channel, error) {
+	var channels []*discordgo.Channel
+	return channels, c.RequestJSON(ctx, "GET", c.BaseURL+EndpointGuildChannels(gid), nil, &channels)
+}
+
+func (c *client) GuildChannelsReorder(ctx context.Context, gid string, positions []ChannelPosition) error {
+	data, err := json.Marshal(positions)
+	if err != nil {
+		return err
+	}
+	_, err = c.Request(ctx, "PATCH", c.BaseURL+EndpointGuildChannels(gid), data)
+	return err
+}
+
+func (c *client) GuildRoles(ctx context.Context, gid string) ([]*discordgo.Role, error) {
+	var roles []*discordgo.Role
+	return roles, c.RequestJSON(ctx, "GET", c.BaseURL+EndpointGuildRoles(gid), nil, &roles)
+}
+
+func (c *client) GuildRoleCreate(ctx context.Context, gid string, opts ...RoleOpt) (*discordgo.Role, error) {
+	return c.requestRole(ctx, "POST", c.BaseURL+EndpointGuildRoles(gid), opts)
+}
+
+func (c *client) GuildRoleEdit(ctx context.Context, gid, rid string, opts ...RoleOpt) (*discordgo.Role, error) {
+	return c.requestRole(ctx, "PATCH", c.BaseURL+EndpointGuildRole(gid, rid), opts)
+}
+
+func (c *client) requestRole(ctx context.Context, method, urlStr string, opts []RoleOpt) (*discordgo.Role, error) {
+	var params discordgo.RoleParams
+	for _, opt := range opts {
+		opt(&params)
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	var role discordgo.Role
+	return &role, c.RequestJSON(ctx, method, urlStr, data, &role)
+}
+
+func (c *client) GuildRolesReorder(ctx context.Context, gid string, positions []RolePosition) ([]*discordgo.Role, error) {
+	data, err := json.Marshal(positions)
+	if err != nil {
+		return nil, err
+	}
+	var roles []*discordgo.Role
+	return roles, c.RequestJSON(ctx, "PATCH", c.BaseURL+EndpointGuildRoles(gid), data, &roles)
+}
+
+func (c *client) GuildRoleDelete(ctx context.Context, gid, rid string) error {
+	_, err := c.Request(ctx, "DELETE", c.BaseURL+EndpointGuildRole(gid, rid), nil)
+	return err
+}
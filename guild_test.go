@@ -0,0 +1,173 @@
+package dgo2poc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientGuild(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"id":"1234","name":"Test","approximate_member_count":42}`)
+	defer srv.Close()
+
+	guild, err := cl.Guild(context.Background(), "1234")
+	require.NoError(t, err)
+	assert.Equal(t, "GET", rec.Last().Method)
+	assert.Equal(t, "/guilds/1234", rec.Last().Path)
+	assert.Equal(t, "with_counts=true", rec.Last().Query)
+	assert.Equal(t, "Test", guild.Name)
+	assert.Equal(t, 42, guild.ApproximateMemberCount)
+}
+
+func TestClientGuildPreview(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"id":"1234","name":"Test"}`)
+	defer srv.Close()
+
+	preview, err := cl.GuildPreview(context.Background(), "1234")
+	require.NoError(t, err)
+	assert.Equal(t, "/guilds/1234/preview", rec.Last().Path)
+	assert.Equal(t, "Test", preview.Name)
+}
+
+func TestClientGuildEdit(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"id":"1234","name":"New Name"}`)
+	defer srv.Close()
+
+	guild, err := cl.GuildEdit(context.Background(), "1234",
+		GuildWithName("New Name"),
+		GuildWithVerificationLevel(discordgo.VerificationLevelNone),
+		GuildWithAFKChannel("5678", 300),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "PATCH", rec.Last().Method)
+	assert.Equal(t, "/guilds/1234", rec.Last().Path)
+	assert.JSONEq(t, `{"name":"New Name","verification_level":0,"afk_channel_id":"5678","afk_timeout":300}`, rec.Last().Body)
+	assert.Equal(t, "New Name", guild.Name)
+
+	t.Run("Zero", func(t *testing.T) {
+		_, err := cl.GuildEdit(context.Background(), "1234",
+			GuildWithDefaultMessageNotifications(discordgo.MessageNotificationsAllMessages),
+			GuildWithExplicitContentFilter(discordgo.ExplicitContentFilterDisabled),
+			GuildWithSystemChannel("5678", 0),
+			GuildWithoutAFKChannel(),
+		)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"default_message_notifications":0,
+			"explicit_content_filter":0,
+			"system_channel_id":"5678",
+			"system_channel_flags":0,
+			"afk_channel_id":null
+		}`, rec.Last().Body)
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		_, err := cl.GuildEdit(context.Background(), "1234",
+			GuildWithoutSystemChannel(),
+			GuildWithDescription(""),
+			GuildWithIcon(""),
+		)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"system_channel_id":null,"description":null,"icon":null}`, rec.Last().Body)
+	})
+}
+
+func TestClientUserGuilds(t *testing.T) {
+	// 250 guilds, with IDs 1-250.
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/users/@me/guilds", req.URL.Path)
+		after, _ := strconv.Atoi(req.URL.Query().Get("after"))
+		var guilds []*discordgo.UserGuild
+		for id := after + 1; id <= 250 && len(guilds) < 200; id++ {
+			guilds = append(guilds, &discordgo.UserGuild{ID: strconv.Itoa(id)})
+		}
+		require.NoError(t, json.NewEncoder(rw).Encode(guilds))
+	}))
+	defer srv.Close()
+
+	guilds, err := newTestClient(srv).UserGuilds(context.Background()).All()
+	require.NoError(t, err)
+	require.Len(t, guilds, 250)
+	assert.Equal(t, "1", guilds[0].ID)
+	assert.Equal(t, "250", guilds[249].ID)
+}
+
+func TestClientGuildChannels(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `[{"id":"1"},{"id":"2"}]`)
+	defer srv.Close()
+	ctx := context.Background()
+
+	channels, err := cl.GuildChannels(ctx, "1234")
+	require.NoError(t, err)
+	assert.Equal(t, "GET", rec.Last().Method)
+	assert.Equal(t, "/guilds/1234/channels", rec.Last().Path)
+	assert.Len(t, channels, 2)
+
+	parent, lock := "5678", true
+	require.NoError(t, cl.GuildChannelsReorder(ctx, "1234", []ChannelPosition{
+		{ID: "1", Position: 1},
+		{ID: "2", Position: 0, ParentID: &parent, LockPermissions: &lock},
+	}))
+	assert.Equal(t, "PATCH", rec.Last().Method)
+	assert.Equal(t, "/guilds/1234/channels", rec.Last().Path)
+	assert.JSONEq(t, `[{"id":"1","position":1},{"id":"2","position":0,"parent_id":"5678","lock_permissions":true}]`, rec.Last().Body)
+}
+
+func TestClientGuildRoles(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `[{"id":"1","name":"@everyone"}]`)
+	defer srv.Close()
+	ctx := context.Background()
+
+	roles, err := cl.GuildRoles(ctx, "1234")
+	require.NoError(t, err)
+	assert.Equal(t, "/guilds/1234/roles", rec.Last().Path)
+	require.Len(t, roles, 1)
+	assert.Equal(t, "@everyone", roles[0].Name)
+
+	roles, err = cl.GuildRolesReorder(ctx, "1234", []RolePosition{{ID: "5678", Position: 2}})
+	require.NoError(t, err)
+	assert.Equal(t, "PATCH", rec.Last().Method)
+	assert.Equal(t, "/guilds/1234/roles", rec.Last().Path)
+	assert.JSONEq(t, `[{"id":"5678","position":2}]`, rec.Last().Body)
+	assert.Len(t, roles, 1)
+}
+
+func TestClientGuildRoleCreateEdit(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"id":"5678","name":"Mods","permissions":"8"}`)
+	defer srv.Close()
+	ctx := context.Background()
+
+	role, err := cl.GuildRoleCreate(ctx, "1234",
+		RoleWithName("Mods"),
+		RoleWithColor(0xFF0000),
+		RoleWithPermissions(discordgo.PermissionAdministrator),
+		RoleWithHoist(true),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "POST", rec.Last().Method)
+	assert.Equal(t, "/guilds/1234/roles", rec.Last().Path)
+	assert.JSONEq(t, `{"name":"Mods","color":16711680,"permissions":"8","hoist":true}`, rec.Last().Body)
+	assert.Equal(t, int64(discordgo.PermissionAdministrator), role.Permissions)
+
+	_, err = cl.GuildRoleEdit(ctx, "1234", "5678", RoleWithMentionable(false))
+	require.NoError(t, err)
+	assert.Equal(t, "PATCH", rec.Last().Method)
+	assert.Equal(t, "/guilds/1234/roles/5678", rec.Last().Path)
+	assert.JSONEq(t, `{"mentionable":false}`, rec.Last().Body)
+}
+
+func TestClientGuildRoleDelete(t *testing.T) {
+	srv, cl, rec := newTestServer(t, "")
+	defer srv.Close()
+
+	require.NoError(t, cl.GuildRoleDelete(context.Background(), "1234", "5678"))
+	assert.Equal(t, "DELETE", rec.Last().Method)
+	assert.Equal(t, "/guilds/1234/roles/5678", rec.Last().Path)
+}
@@ -0,0 +1,160 @@
+package dgo2poc
+
+import (
+	"context"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pkg/errors"
+)
+
+// Interaction time limits. The initial response must be sent within InteractionResponseTimeout,
+// after which the interaction's token can be used for follow-ups until InteractionTokenLifetime.
+const (
+	InteractionResponseTimeout = 3 * time.Second
+	InteractionTokenLifetime   = 15 * time.Minute
+)
+
+// The maximum number of choices that can be returned for an autocomplete interaction.
+const AutocompleteMaxChoices = 25
+
+// An initial response to an interaction.
+type interactionResponse struct {
+	Type discordgo.InteractionResponseType `json:"type"`
+	Data interface{}                       `json:"data,omitempty"`
+}
+
+// Checks the response's data, if it's a message.
+func (res interactionResponse) validate() error {
+	if v, ok := res.Data.(interface{ validate() error }); ok {
+		return v.validate()
+	}
+	return nil
+}
+
+// Data for a deferred response.
+type deferredResponseData struct {
+	Flags discordgo.MessageFlags `json:"flags,omitempty"`
+}
+
+// Data for an autocomplete response.
+type autocompleteResponseData struct {
+	Choices []*discordgo.ApplicationCommandOptionChoice `json:"choices"`
+}
+
+// Data for a modal response.
+type modalResponseData struct {
+	CustomID   string                       `json:"custom_id"`
+	Title      string                       `json:"title"`
+	Components []discordgo.MessageComponent `json:"components"`
+}
+
+func (c *client) InteractionRespond(ctx context.Context, i *discordgo.Interaction, content string, opts ...SendOpt) error {
+	send := newMessageSend(content, opts)
+	send.Attachments = append(send.Attachments, fileAttachments(send.Files)...)
+	return c.interactionCallback(ctx, i, interactionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: send,
+	}, send.Files)
+}
+
+func (c *client) InteractionDefer(ctx context.Context, i *discordgo.Interaction, opts ...SendOpt) error {
+	var send MessageSend
+	for _, opt := range opts {
+		opt(&send)
+	}
+	res := interactionResponse{Type: discordgo.InteractionResponseDeferredChannelMessageWithSource}
+	if i.Type == discordgo.InteractionMessageComponent || i.Type == discordgo.InteractionModalSubmit {
+		res.Type = discordgo.InteractionResponseDeferredMessageUpdate
+	}
+	if send.Flags != 0 {
+		res.Data = deferredResponseData{Flags: send.Flags}
+	}
+	return c.interactionCallback(ctx, i, res, nil)
+}
+
+func (c *client) InteractionUpdate(ctx context.Context, i *discordgo.Interaction, opts ...EditOpt) error {
+	edit := newMessageEdit(opts)
+	return c.interactionCallback(ctx, i, interactionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: edit,
+	}, edit.Files)
+}
+
+func (c *client) InteractionRespondModal(ctx context.Context, i *discordgo.Interaction, customID, title string, components ...discordgo.MessageComponent) error {
+	return c.interactionCallback(ctx, i, interactionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: modalResponseData{CustomID: customID, Title: title, Components: components},
+	}, nil)
+}
+
+func (c *client) InteractionAutocomplete(ctx context.Context, i *discordgo.Interaction, choices ...*discordgo.ApplicationCommandOptionChoice) error {
+	if choices == nil {
+		choices = []*discordgo.ApplicationCommandOptionChoice{}
+	}
+	return c.interactionCallback(ctx, i, interactionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: autocompleteResponseData{Choices: choices},
+	}, nil)
+}
+
+// Sends an initial response to an interaction.
+func (c *client) interactionCallback(ctx context.Context, i *discordgo.Interaction, res interactionResponse, files []*File) error {
+	err := c.requestMessage(ctx, "POST", c.BaseURL+EndpointInteractionCallback(i.ID, i.Token), res, files, nil)
+	return interactionError(i, err, true)
+}
+
+func (c *client) InteractionResponse(ctx context.Context, i *discordgo.Interaction) (*discordgo.Message, error) {
+	msg, err := c.interactionWebhook(i).Message(ctx, "@original")
+	return msg, interactionError(i, err, false)
+}
+
+func (c *client) InteractionResponseEdit(ctx context.Context, i *discordgo.Interaction, opts ...EditOpt) (*discordgo.Message, error) {
+	msg, err := c.interactionWebhook(i).MessageEdit(ctx, "@original", opts...)
+	return msg, interactionError(i, err, false)
+}
+
+func (c *client) InteractionResponseDelete(ctx context.Context, i *discordgo.Interaction) error {
+	return interactionError(i, c.interactionWebhook(i).MessageDelete(ctx, "@original"), false)
+}
+
+func (c *client) InteractionFollowup(ctx context.Context, i *discordgo.Interaction, content string, opts ...SendOpt) (*discordgo.Message, error) {
+	msg, err := c.interactionWebhook(i).Execute(ctx, content, opts...)
+	return msg, interactionError(i, err, false)
+}
+
+func (c *client) InteractionFollowupEdit(ctx context.Context, i *discordgo.Interaction, id string, opts ...EditOpt) (*discordgo.Message, error) {
+	msg, err := c.interactionWebhook(i).MessageEdit(ctx, id, opts...)
+	return msg, interactionError(i, err, false)
+}
+
+func (c *client) InteractionFollowupDelete(ctx context.Context, i *discordgo.Interaction, id string) error {
+	return interactionError(i, c.interactionWebhook(i).MessageDelete(ctx, id), false)
+}
+
+// Follow-ups are sent through a webhook, identified by the application ID and interaction token.
+func (c *client) interactionWebhook(i *discordgo.Interaction) *webhookClient {
+	return &webhookClient{client: c, ID: i.AppID, WebToken: i.Token}
+}
+
+// Turns errors caused by an expired interaction into an *InteractionExpiredError.
+// Discord reports these as unknown interactions/webhooks, or invalid tokens.
+func interactionError(i *discordgo.Interaction, err error, initial bool) error {
+	var httpErr *HTTPError
+	if err == nil || !errors.As(err, &httpErr) {
+		return err
+	}
+	expired := &InteractionExpiredError{HTTPError: httpErr}
+	if created, perr := SnowflakeTime(i.ID); perr == nil {
+		expired.Age = time.Since(created)
+	}
+	switch code := ErrorCode(err); {
+	case initial && code == APIErrorUnknownInteraction:
+		expired.Err = ErrInteractionTimeout
+	case !initial && (code == APIErrorUnknownWebhook || code == APIErrorInvalidWebhookToken):
+		expired.Err = ErrInteractionTokenExpired
+	default:
+		return err
+	}
+	return expired
+}
@@ -0,0 +1,115 @@
+package dgo2poc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pkg/errors"
+)
+
+// Max size of an interaction request body; anything larger is rejected.
+const maxInteractionSize = 1 << 20
+
+// Handles an interaction received over HTTP. The returned response is sent back to Discord as the
+// initial response; return nil if you've already responded another way, eg. through the Client.
+// The context is the request's, and is cancelled once the handler returns; follow-ups sent from
+// other goroutines must use a context of their own, eg. context.Background().
+type InteractionHandlerFunc func(ctx context.Context, i *discordgo.Interaction) *discordgo.InteractionResponse
+
+// Parses an application's public key, as shown in the developer portal.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "public key")
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, errors.Errorf("public key: wrong size: %d", len(key))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+type interactionHandler struct {
+	REST      Client
+	PublicKey ed25519.PublicKey
+	Handler   InteractionHandlerFunc
+}
+
+// Returns an http.Handler that receives interactions, for use as an application's Interactions
+// Endpoint URL. Requests are verified against the application's public key, PINGs are answered,
+// and all other interactions are passed to fn, with a context carrying the Client; see GetClient().
+// If publicKey isn't a valid key, eg. nil, every request fails with a 500.
+func NewInteractionHandler(cl Client, publicKey ed25519.PublicKey, fn InteractionHandlerFunc) http.Handler {
+	if len(publicKey) != ed25519.PublicKeySize {
+		log.Printf("interactions: public key: wrong size: %d", len(publicKey))
+	}
+	return &interactionHandler{REST: cl, PublicKey: publicKey, Handler: fn}
+}
+
+func (h *interactionHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if len(h.PublicKey) != ed25519.PublicKeySize {
+		http.Error(rw, "misconfigured public key", http.StatusInternalServerError)
+		return
+	}
+	if req.Method != "POST" {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(rw, req.Body, maxInteractionSize))
+	if err != nil {
+		http.Error(rw, "couldn't read request", http.StatusBadRequest)
+		return
+	}
+
+	// Discord periodically sends requests with bad signatures, and disables endpoints that accept them.
+	if !verifyInteraction(h.PublicKey, req.Header, body) {
+		http.Error(rw, "invalid request signature", http.StatusUnauthorized)
+		return
+	}
+
+	var i discordgo.Interaction
+	if err := json.Unmarshal(body, &i); err != nil {
+		http.Error(rw, "couldn't decode interaction", http.StatusBadRequest)
+		return
+	}
+
+	var res *discordgo.InteractionResponse
+	if i.Type == discordgo.InteractionPing {
+		res = &discordgo.InteractionResponse{Type: discordgo.InteractionResponsePong}
+	} else {
+		res = h.Handler(withClient(req.Context(), h.REST), &i)
+	}
+	if res == nil {
+		rw.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("interactions: couldn't encode response: %v", err)
+		http.Error(rw, "couldn't encode response", http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	_, _ = rw.Write(data)
+}
+
+// Returns whether an interaction request was signed with the given public key.
+func verifyInteraction(key ed25519.PublicKey, h http.Header, body []byte) bool {
+	sig, err := hex.DecodeString(h.Get("X-Signature-Ed25519"))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return false
+	}
+	ts := h.Get("X-Signature-Timestamp")
+	if ts == "" {
+		return false
+	}
+	msg := make([]byte, 0, len(ts)+len(body))
+	msg = append(append(msg, ts...), body...)
+	return ed25519.Verify(key, msg, sig)
+}
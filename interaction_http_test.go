@@ -0,0 +1,127 @@
+package dgo2poc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Returns a signed interaction request, as sent by Discord.
+func newSignedInteractionRequest(t *testing.T, key ed25519.PrivateKey, body string) *http.Request {
+	ts := "1672531200"
+	sig := ed25519.Sign(key, []byte(ts+body))
+	req := httptest.NewRequest("POST", "/interactions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature-Ed25519", hex.EncodeToString(sig))
+	req.Header.Set("X-Signature-Timestamp", ts)
+	return req
+}
+
+func TestParsePublicKey(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := ParsePublicKey(hex.EncodeToString(pub))
+	require.NoError(t, err)
+	assert.Equal(t, pub, key)
+
+	_, err = ParsePublicKey("zz")
+	assert.Error(t, err)
+	_, err = ParsePublicKey("abcd")
+	assert.EqualError(t, err, "public key: wrong size: 2")
+}
+
+func TestInteractionHandler(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	cl := NewClient(BotToken("hi"))
+
+	var got *discordgo.Interaction
+	h := NewInteractionHandler(cl, pub, func(ctx context.Context, i *discordgo.Interaction) *discordgo.InteractionResponse {
+		assert.Equal(t, cl, GetClient(ctx))
+		got = i
+		return &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{Content: "pong!"},
+		}
+	})
+
+	t.Run("Ping", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, newSignedInteractionRequest(t, priv, `{"id":"1234","type":1}`))
+		assert.Equal(t, http.StatusOK, rw.Code)
+		assert.JSONEq(t, `{"type":1}`, rw.Body.String())
+	})
+
+	t.Run("Command", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, newSignedInteractionRequest(t, priv, `{
+			"id": "1234",
+			"type": 2,
+			"token": "abcd",
+			"data": {"id": "5678", "name": "ping", "type": 1}
+		}`))
+		assert.Equal(t, http.StatusOK, rw.Code)
+		assert.Equal(t, "application/json", rw.Header().Get("Content-Type"))
+		assert.Contains(t, rw.Body.String(), `"content":"pong!"`)
+
+		require.NotNil(t, got)
+		assert.Equal(t, "abcd", got.Token)
+		assert.Equal(t, "ping", got.ApplicationCommandData().Name)
+	})
+
+	t.Run("NoResponse", func(t *testing.T) {
+		h := NewInteractionHandler(cl, pub, func(ctx context.Context, i *discordgo.Interaction) *discordgo.InteractionResponse {
+			return nil
+		})
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, newSignedInteractionRequest(t, priv, `{"id":"1234","type":3,"data":{"custom_id":"x"}}`))
+		assert.Equal(t, http.StatusAccepted, rw.Code)
+	})
+
+	t.Run("BadSignature", func(t *testing.T) {
+		_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, newSignedInteractionRequest(t, otherKey, `{"id":"1234","type":1}`))
+		assert.Equal(t, http.StatusUnauthorized, rw.Code)
+	})
+
+	t.Run("TamperedBody", func(t *testing.T) {
+		req := newSignedInteractionRequest(t, priv, `{"id":"1234","type":1}`)
+		req.Body = ioutil.NopCloser(strings.NewReader(`{"id":"1235","type":1}`))
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, req)
+		assert.Equal(t, http.StatusUnauthorized, rw.Code)
+	})
+
+	t.Run("MissingHeaders", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, httptest.NewRequest("POST", "/interactions", strings.NewReader(`{"type":1}`)))
+		assert.Equal(t, http.StatusUnauthorized, rw.Code)
+	})
+
+	t.Run("BadPublicKey", func(t *testing.T) {
+		h := NewInteractionHandler(cl, nil, func(ctx context.Context, i *discordgo.Interaction) *discordgo.InteractionResponse {
+			return nil
+		})
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, newSignedInteractionRequest(t, priv, `{"id":"1234","type":1}`))
+		assert.Equal(t, http.StatusInternalServerError, rw.Code)
+	})
+
+	t.Run("WrongMethod", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, httptest.NewRequest("GET", "/interactions", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+	})
+}
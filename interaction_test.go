@@ -0,0 +1,186 @@
+package dgo2poc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Returns a test interaction, created at the given time.
+func newTestInteraction(typ discordgo.InteractionType, created time.Time) *discordgo.Interaction {
+	return &discordgo.Interaction{ID: SnowflakeFromTime(created), AppID: "1111", Type: typ, Token: "tok"}
+}
+
+func TestClientInteractionRespond(t *testing.T) {
+	srv, cl, rec := newTestServer(t, "")
+	defer srv.Close()
+	ctx := context.Background()
+	i := newTestInteraction(discordgo.InteractionApplicationCommand, time.Now())
+
+	require.NoError(t, cl.InteractionRespond(ctx, i, "hi", SendWithFlags(discordgo.MessageFlagsEphemeral)))
+	assert.Equal(t, "POST", rec.Last().Method)
+	assert.Equal(t, "/interactions/"+i.ID+"/tok/callback", rec.Last().Path)
+	assert.JSONEq(t, `{
+		"type": 4,
+		"data": {"content":"hi","tts":false,"embeds":null,"components":null,"flags":64}
+	}`, rec.Last().Body)
+
+	t.Run("File", func(t *testing.T) {
+		require.NoError(t, cl.InteractionRespond(ctx, i, "hi", SendWithFile("test.txt", strings.NewReader("hello"))))
+		assert.Contains(t, rec.Last().Header.Get("Content-Type"), "multipart/form-data")
+		assert.Contains(t, rec.Last().Body, "hello")
+	})
+}
+
+func TestClientInteractionDefer(t *testing.T) {
+	srv, cl, rec := newTestServer(t, "")
+	defer srv.Close()
+	ctx := context.Background()
+
+	cmd := newTestInteraction(discordgo.InteractionApplicationCommand, time.Now())
+	require.NoError(t, cl.InteractionDefer(ctx, cmd, SendWithFlags(discordgo.MessageFlagsEphemeral)))
+	assert.JSONEq(t, `{"type":5,"data":{"flags":64}}`, rec.Last().Body)
+
+	comp := newTestInteraction(discordgo.InteractionMessageComponent, time.Now())
+	require.NoError(t, cl.InteractionDefer(ctx, comp))
+	assert.JSONEq(t, `{"type":6}`, rec.Last().Body)
+}
+
+func TestClientInteractionUpdate(t *testing.T) {
+	srv, cl, rec := newTestServer(t, "")
+	defer srv.Close()
+
+	i := newTestInteraction(discordgo.InteractionMessageComponent, time.Now())
+	require.NoError(t, cl.InteractionUpdate(context.Background(), i, EditWithContent("clicked")))
+	assert.JSONEq(t, `{"type":7,"data":{"content":"clicked"}}`, rec.Last().Body)
+}
+
+func TestClientInteractionRespondModal(t *testing.T) {
+	srv, cl, rec := newTestServer(t, "")
+	defer srv.Close()
+
+	i := newTestInteraction(discordgo.InteractionApplicationCommand, time.Now())
+	require.NoError(t, cl.InteractionRespondModal(context.Background(), i, "feedback", "Feedback",
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.TextInput{CustomID: "text", Label: "Text", Style: discordgo.TextInputShort},
+		}},
+	))
+	assert.JSONEq(t, `{
+		"type": 9,
+		"data": {
+			"custom_id": "feedback",
+			"title": "Feedback",
+			"components": [{"type":1,"components":[{"type":4,"custom_id":"text","label":"Text","style":1,"required":false}]}]
+		}
+	}`, rec.Last().Body)
+}
+
+func TestClientInteractionAutocomplete(t *testing.T) {
+	srv, cl, rec := newTestServer(t, "")
+	defer srv.Close()
+	ctx := context.Background()
+	i := newTestInteraction(discordgo.InteractionApplicationCommandAutocomplete, time.Now())
+
+	require.NoError(t, cl.InteractionAutocomplete(ctx, i,
+		&discordgo.ApplicationCommandOptionChoice{Name: "Cat", Value: "cat"},
+		&discordgo.ApplicationCommandOptionChoice{Name: "Dog", Value: "dog"},
+	))
+	assert.Equal(t, "/interactions/"+i.ID+"/tok/callback", rec.Last().Path)
+	assert.JSONEq(t, `{"type":8,"data":{"choices":[{"name":"Cat","value":"cat"},{"name":"Dog","value":"dog"}]}}`, rec.Last().Body)
+
+	require.NoError(t, cl.InteractionAutocomplete(ctx, i))
+	assert.JSONEq(t, `{"type":8,"data":{"choices":[]}}`, rec.Last().Body)
+}
+
+func TestClientInteractionFollowups(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"id":"5678"}`)
+	defer srv.Close()
+	ctx := context.Background()
+	i := newTestInteraction(discordgo.InteractionApplicationCommand, time.Now())
+
+	_, err := cl.InteractionResponse(ctx, i)
+	require.NoError(t, err)
+	assert.Equal(t, "GET", rec.Last().Method)
+	assert.Equal(t, "/webhooks/1111/tok/messages/@original", rec.Last().Path)
+
+	_, err = cl.InteractionResponseEdit(ctx, i, EditWithContent("done"))
+	require.NoError(t, err)
+	assert.Equal(t, "PATCH", rec.Last().Method)
+	assert.Equal(t, "/webhooks/1111/tok/messages/@original", rec.Last().Path)
+
+	require.NoError(t, cl.InteractionResponseDelete(ctx, i))
+	assert.Equal(t, "DELETE", rec.Last().Method)
+	assert.Equal(t, "/webhooks/1111/tok/messages/@original", rec.Last().Path)
+
+	msg, err := cl.InteractionFollowup(ctx, i, "more", SendWithFlags(discordgo.MessageFlagsEphemeral))
+	require.NoError(t, err)
+	assert.Equal(t, "5678", msg.ID)
+	assert.Equal(t, "POST", rec.Last().Method)
+	assert.Equal(t, "/webhooks/1111/tok", rec.Last().Path)
+	assert.Equal(t, "wait=true", rec.Last().Query)
+
+	_, err = cl.InteractionFollowupEdit(ctx, i, "5678", EditWithContent("less"))
+	require.NoError(t, err)
+	assert.Equal(t, "PATCH", rec.Last().Method)
+	assert.Equal(t, "/webhooks/1111/tok/messages/5678", rec.Last().Path)
+
+	require.NoError(t, cl.InteractionFollowupDelete(ctx, i, "5678"))
+	assert.Equal(t, "DELETE", rec.Last().Method)
+	assert.Equal(t, "/webhooks/1111/tok/messages/5678", rec.Last().Path)
+}
+
+func TestClientInteractionExpired(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusNotFound)
+		if strings.HasPrefix(req.URL.Path, "/interactions/") {
+			_, _ = rw.Write([]byte(`{"code":10062,"message":"Unknown interaction"}`))
+		} else {
+			_, _ = rw.Write([]byte(`{"code":10015,"message":"Unknown Webhook"}`))
+		}
+	}))
+	defer srv.Close()
+	cl := newTestClient(srv)
+	ctx := context.Background()
+
+	t.Run("Timeout", func(t *testing.T) {
+		i := newTestInteraction(discordgo.InteractionApplicationCommand, time.Now().Add(-5*time.Second))
+		err := cl.InteractionRespond(ctx, i, "too late")
+		assert.True(t, errors.Is(err, ErrInteractionTimeout), "%v", err)
+		assert.True(t, errors.Is(err, ErrNotFound), "%v", err)
+		assert.False(t, errors.Is(err, ErrInteractionTokenExpired), "%v", err)
+
+		var expired *InteractionExpiredError
+		require.True(t, errors.As(err, &expired))
+		assert.True(t, expired.Age >= 5*time.Second, "%s", expired.Age)
+		assert.Equal(t, APIErrorUnknownInteraction, ErrorCode(err))
+	})
+
+	t.Run("TokenExpired", func(t *testing.T) {
+		i := newTestInteraction(discordgo.InteractionApplicationCommand, time.Now().Add(-20*time.Minute))
+		_, err := cl.InteractionFollowup(ctx, i, "too late")
+		assert.True(t, errors.Is(err, ErrInteractionTokenExpired), "%v", err)
+		assert.False(t, errors.Is(err, ErrInteractionTimeout), "%v", err)
+		assert.Contains(t, err.Error(), "interaction token has expired after 20m")
+	})
+}
+
+func TestDispatchInteractionCreate(t *testing.T) {
+	var pre, main wsHandlers
+	var got *InteractionCreate
+	pre.Add(OnInteractionCreate(func(ctx context.Context, ev *InteractionCreate) {
+		got = ev
+	}))
+	data := []byte(`{"id":"1234","type":2,"token":"tok","data":{"id":"5678","name":"ping","type":1}}`)
+	require.NoError(t, dispatch(context.Background(), "INTERACTION_CREATE", data, &pre, &main))
+	require.NotNil(t, got)
+	assert.Equal(t, "tok", got.Token)
+	assert.Equal(t, "ping", got.ApplicationCommandData().Name)
+}
@@ -0,0 +1,182 @@
+package dgo2poc
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// Fetches a page of up to limit items, starting at a cursor ("" for the first page).
+// Returns the items and the cursor for the next page, or "" if there are no more pages.
+type pageFunc[T any] func(ctx context.Context, cursor string, limit int) ([]T, string, error)
+
+// Iterator over a paginated list, eg. messages in a channel. Pages are fetched lazily, as needed:
+//
+//	it := cl.ChannelMessages(ctx, cid).Limit(500)
+//	for it.Next() {
+//		log.Printf("%s: %s", it.Value().Author.Username, it.Value().Content)
+//	}
+//	if err := it.Err(); err != nil {
+//		// ...
+//	}
+//
+// Or, to fetch everything at once:
+//
+//	msgs, err := cl.ChannelMessages(ctx, cid).Limit(500).All()
+type Iterator[T any] struct {
+	ctx      context.Context
+	fetch    pageFunc[T]
+	pageSize int
+
+	limit int          // max number of items to return, 0 for no limit
+	while func(T) bool // stop at the first item this returns false for
+
+	cursor string
+	page   []T
+	value  T
+	n      int // number of items returned so far
+	done   bool
+	err    error
+}
+
+// Creates an iterator that fetches pages of up to pageSize items.
+func newIterator[T any](ctx context.Context, pageSize int, fetch pageFunc[T]) *Iterator[T] {
+	return &Iterator[T]{ctx: ctx, fetch: fetch, pageSize: pageSize}
+}
+
+// Stop after n items. Returns the iterator, for chaining.
+func (it *Iterator[T]) Limit(n int) *Iterator[T] {
+	it.limit = n
+	return it
+}
+
+// Stop at the first item fn returns false for. Returns the iterator, for chaining.
+func (it *Iterator[T]) While(fn func(v T) bool) *Iterator[T] {
+	it.while = fn
+	return it
+}
+
+// Advances to the next item, fetching another page if needed. Returns false when there are no
+// more items, the limit or predicate was reached, or an error occurred; see Err().
+func (it *Iterator[T]) Next() bool {
+	var zero T
+	if it.limit > 0 && it.n >= it.limit {
+		it.done = true
+	}
+	if len(it.page) == 0 && !it.done && it.err == nil {
+		it.fetchPage()
+	}
+	if len(it.page) == 0 {
+		it.value = zero
+		return false
+	}
+
+	v := it.page[0]
+	if it.while != nil && !it.while(v) {
+		it.page, it.value, it.done = nil, zero, true
+		return false
+	}
+	it.value, it.page = v, it.page[1:]
+	it.n++
+	return true
+}
+
+// Fetches the next page.
+func (it *Iterator[T]) fetchPage() {
+	limit := it.pageSize
+	if it.limit > 0 && it.limit-it.n < limit {
+		limit = it.limit - it.n
+	}
+	page, next, err := it.fetch(it.ctx, it.cursor, limit)
+	if err != nil {
+		it.err = err
+		return
+	}
+	if len(page) < limit || next == "" {
+		it.done = true
+	}
+	it.cursor = next
+	it.page = page
+}
+
+// Returns the current item.
+func (it *Iterator[T]) Value() T {
+	return it.value
+}
+
+// Returns the error that stopped the iterator, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Returns all remaining items.
+func (it *Iterator[T]) All() ([]T, error) {
+	var all []T
+	for it.Next() {
+		all = append(all, it.Value())
+	}
+	return all, it.Err()
+}
+
+// Calls fn for each remaining item, stopping if it returns an error.
+func (it *Iterator[T]) ForEach(fn func(v T) error) error {
+	for it.Next() {
+		if err := fn(it.Value()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// Returns a pageFunc for a list endpoint that pages by snowflake. For param "before", items are
+// returned newest first, for "after", oldest first. If start is given, the first page starts
+// there. The id function returns an item's ID.
+func pageSnowflakes[T any](cl Client, urlStr string, q url.Values, param, start string, id func(v T) string) pageFunc[T] {
+	return pageSnowflakesWith(func(ctx context.Context, urlStr string) ([]T, error) {
+		var page []T
+		return page, cl.RequestJSON(ctx, "GET", urlStr, nil, &page)
+	}, urlStr, q, param, start, id)
+}
+
+// Like pageSnowflakes(), for endpoints that don't return a plain list; get fetches a page.
+func pageSnowflakesWith[T any](get func(ctx context.Context, urlStr string) ([]T, error), urlStr string, q url.Values, param, start string, id func(v T) string) pageFunc[T] {
+	return func(ctx context.Context, cursor string, limit int) ([]T, string, error) {
+		if cursor == "" {
+			cursor = start
+		}
+		pq := url.Values{}
+		for k, v := range q {
+			pq[k] = v
+		}
+		pq.Set("limit", strconv.Itoa(limit))
+		if cursor != "" {
+			pq.Set(param, cursor)
+		}
+
+		page, err := get(ctx, urlStr+"?"+pq.Encode())
+		if err != nil {
+			return nil, "", err
+		}
+
+		// Don't rely on the order of items; eg. messages are always returned newest first.
+		sort.SliceStable(page, func(i, j int) bool {
+			if param == "before" {
+				return snowflakeLess(id(page[j]), id(page[i]))
+			}
+			return snowflakeLess(id(page[i]), id(page[j]))
+		})
+		if len(page) == 0 {
+			return page, "", nil
+		}
+		return page, id(page[len(page)-1]), nil
+	}
+}
+
+// Returns whether snowflake a is lower than b.
+func snowflakeLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
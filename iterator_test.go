@@ -0,0 +1,98 @@
+package dgo2poc
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Returns an iterator over the numbers 1-n, along with a pointer to the number of pages fetched.
+func newTestIterator(ctx context.Context, n, pageSize int) (*Iterator[int], *int) {
+	var pages int
+	return newIterator(ctx, pageSize, func(ctx context.Context, cursor string, limit int) ([]int, string, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, "", err
+		}
+		pages++
+		start, _ := strconv.Atoi(cursor)
+		var page []int
+		for i := start + 1; i <= n && len(page) < limit; i++ {
+			page = append(page, i)
+		}
+		if len(page) == 0 {
+			return nil, "", nil
+		}
+		return page, strconv.Itoa(page[len(page)-1]), nil
+	}), &pages
+}
+
+func TestIterator(t *testing.T) {
+	it, pages := newTestIterator(context.Background(), 25, 10)
+	var n int
+	for it.Next() {
+		n++
+		assert.Equal(t, n, it.Value())
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, 25, n)
+	assert.Equal(t, 3, *pages)
+	assert.False(t, it.Next())
+
+	t.Run("Exact", func(t *testing.T) {
+		it, pages := newTestIterator(context.Background(), 20, 10)
+		all, err := it.All()
+		require.NoError(t, err)
+		assert.Len(t, all, 20)
+		assert.Equal(t, 3, *pages)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		it, pages := newTestIterator(context.Background(), 25, 10)
+		all, err := it.Limit(15).All()
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}, all)
+		assert.Equal(t, 2, *pages)
+	})
+
+	t.Run("While", func(t *testing.T) {
+		it, pages := newTestIterator(context.Background(), 25, 10)
+		all, err := it.While(func(v int) bool { return v < 5 }).All()
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3, 4}, all)
+		assert.Equal(t, 1, *pages)
+	})
+
+	t.Run("ForEach", func(t *testing.T) {
+		it, _ := newTestIterator(context.Background(), 25, 10)
+		var sum int
+		assert.NoError(t, it.ForEach(func(v int) error {
+			sum += v
+			return nil
+		}))
+		assert.Equal(t, 325, sum)
+
+		it, _ = newTestIterator(context.Background(), 25, 10)
+		assert.EqualError(t, it.ForEach(func(v int) error {
+			if v == 12 {
+				return errors.New("oh no")
+			}
+			return nil
+		}), "oh no")
+		assert.Equal(t, 12, it.Value())
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		it, _ := newTestIterator(ctx, 25, 10)
+		for i := 0; i < 10; i++ {
+			require.True(t, it.Next())
+		}
+		cancel()
+		assert.False(t, it.Next())
+		assert.Equal(t, context.Canceled, it.Err())
+	})
+}
@@ -0,0 +1,248 @@
+package dgo2poc
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Max number of members returned by Client.GuildMemberSearch().
+const MemberSearchMaxLimit = 1000
+
+// An edit to a guild member; see Client.GuildMemberEdit().
+type MemberEdit struct {
+	discordgo.GuildMemberParams
+
+	// Reset the member's nickname; GuildMemberParams can't express this.
+	ResetNick bool `json:"-"`
+
+	// Reason shown in the guild's audit log.
+	Reason string `json:"-"`
+}
+
+// Returns the edit's JSON payload.
+func (edit *MemberEdit) payload() ([]byte, error) {
+	data, err := json.Marshal(edit.GuildMemberParams)
+	if err != nil || !edit.ResetNick {
+		return data, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["nick"] = json.RawMessage(`null`)
+	return json.Marshal(fields)
+}
+
+// Options for Client.GuildMemberEdit().
+type MemberOpt func(edit *MemberEdit)
+
+// Set a member's nickname. Use "" to reset it.
+func MemberWithNick(nick string) MemberOpt {
+	return MemberOpt(func(edit *MemberEdit) {
+		edit.Nick = nick
+		edit.ResetNick = nick == ""
+	})
+}
+
+// Replace a member's roles. Call with no IDs to remove all roles.
+func MemberWithRoles(ids ...string) MemberOpt {
+	return MemberOpt(func(edit *MemberEdit) {
+		roles := append([]string{}, ids...)
+		edit.Roles = &roles
+	})
+}
+
+// Mute or unmute a member in voice channels.
+func MemberWithMute(mute bool) MemberOpt {
+	return MemberOpt(func(edit *MemberEdit) {
+		edit.Mute = &mute
+	})
+}
+
+// Deafen or undeafen a member in voice channels.
+func MemberWithDeaf(deaf bool) MemberOpt {
+	return MemberOpt(func(edit *MemberEdit) {
+		edit.Deaf = &deaf
+	})
+}
+
+// Move a member to another voice channel. Use "" to disconnect them from voice.
+func MemberWithVoiceChannel(channel string) MemberOpt {
+	return MemberOpt(func(edit *MemberEdit) {
+		edit.ChannelID = &channel
+	})
+}
+
+// Time a member out until the given time, at most 28 days in the future.
+// Use the zero time to remove a timeout.
+func MemberWithTimeout(until time.Time) MemberOpt {
+	return MemberOpt(func(edit *MemberEdit) {
+		edit.CommunicationDisabledUntil = &until
+	})
+}
+
+// Set the reason shown in the guild's audit log.
+func MemberWithReason(reason string) MemberOpt {
+	return MemberOpt(func(edit *MemberEdit) {
+		edit.Reason = reason
+	})
+}
+
+// Options for a prune; see Client.GuildPruneCount() and Client.GuildPrune().
+type PruneQuery struct {
+	// Members inactive for this many days (1-30) are pruned. Defaults to 7.
+	Days int `json:"days,omitempty"`
+
+	// By default, members with roles aren't pruned; members with these roles are included.
+	IncludeRoles []string `json:"include_roles,omitempty"`
+
+	// Whether Client.GuildPrune() should return the number of pruned members. Discord recommends
+	// turning this off for large guilds, as it slows the request down.
+	ComputeCount bool `json:"compute_prune_count"`
+
+	// Reason shown in the guild's audit log.
+	Reason string `json:"-"`
+}
+
+// Options for Client.GuildPruneCount() and Client.GuildPrune().
+type PruneOpt func(q *PruneQuery)
+
+// Prune members inactive for the given number of days (1-30), instead of the default 7.
+func PruneDays(days int) PruneOpt {
+	return PruneOpt(func(q *PruneQuery) {
+		q.Days = days
+	})
+}
+
+// Also prune members with the given roles; by default, members with any roles are skipped.
+func PruneIncludeRoles(ids ...string) PruneOpt {
+	return PruneOpt(func(q *PruneQuery) {
+		q.IncludeRoles = append(q.IncludeRoles, ids...)
+	})
+}
+
+// Don't count pruned members; Client.GuildPrune() returns 0 instead. Recommended for large guilds.
+func PruneWithoutCount() PruneOpt {
+	return PruneOpt(func(q *PruneQuery) {
+		q.ComputeCount = false
+	})
+}
+
+// Set the reason shown in the guild's audit log.
+func PruneWithReason(reason string) PruneOpt {
+	return PruneOpt(func(q *PruneQuery) {
+		q.Reason = reason
+	})
+}
+
+// Returns a PruneQuery with the given options applied.
+func newPruneQuery(opts []PruneOpt) PruneQuery {
+	q := PruneQuery{ComputeCount: true}
+	for _, opt := range opts {
+		opt(&q)
+	}
+	return q
+}
+
+// Returns the query as a query string, including the leading "?", or "" if it's empty.
+func (q PruneQuery) Encode() string {
+	v := url.Values{}
+	if q.Days != 0 {
+		v.Set("days", strconv.Itoa(q.Days))
+	}
+	for _, id := range q.IncludeRoles {
+		v.Add("include_roles", id)
+	}
+	if len(v) == 0 {
+		return ""
+	}
+	return "?" + v.Encode()
+}
+
+func (c *client) GuildMember(ctx context.Context, gid, uid string) (*discordgo.Member, error) {
+	var member discordgo.Member
+	return &member, c.RequestJSON(ctx, "GET", c.BaseURL+EndpointGuildMember(gid, uid), nil, &member)
+}
+
+func (c *client) GuildMembers(ctx context.Context, gid string) *Iterator[*discordgo.Member] {
+	urlStr := c.BaseURL + EndpointGuildMembers(gid)
+	return newIterator(ctx, 1000, pageSnowflakes(c, urlStr, nil, "after", "", memberID))
+}
+
+func (c *client) GuildMemberSearch(ctx context.Context, gid, query string, limit int) ([]*discordgo.Member, error) {
+	q := url.Values{"query": {query}}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var members []*discordgo.Member
+	return members, c.RequestJSON(ctx, "GET", c.BaseURL+EndpointGuildMembersSearch(gid)+"?"+q.Encode(), nil, &members)
+}
+
+func (c *client) GuildMemberEdit(ctx context.Context, gid, uid string, opts ...MemberOpt) (*discordgo.Member, error) {
+	var edit MemberEdit
+	for _, opt := range opts {
+		opt(&edit)
+	}
+	data, err := edit.payload()
+	if err != nil {
+		return nil, err
+	}
+	var member discordgo.Member
+	return &member, c.RequestJSON(ctx, "PATCH", c.BaseURL+EndpointGuildMember(gid, uid), data, &member,
+		WithAuditLogReason(edit.Reason))
+}
+
+func (c *client) GuildMemberRoleAdd(ctx context.Context, gid, uid, rid, reason string) error {
+	_, err := c.Request(ctx, "PUT", c.BaseURL+EndpointGuildMemberRole(gid, uid, rid), nil,
+		WithAuditLogReason(reason))
+	return err
+}
+
+func (c *client) GuildMemberRoleRemove(ctx context.Context, gid, uid, rid, reason string) error {
+	_, err := c.Request(ctx, "DELETE", c.BaseURL+EndpointGuildMemberRole(gid, uid, rid), nil,
+		WithAuditLogReason(reason))
+	return err
+}
+
+func (c *client) GuildMemberKick(ctx context.Context, gid, uid, reason string) error {
+	_, err := c.Request(ctx, "DELETE", c.BaseURL+EndpointGuildMember(gid, uid), nil,
+		WithAuditLogReason(reason))
+	return err
+}
+
+func (c *client) GuildPruneCount(ctx context.Context, gid string, opts ...PruneOpt) (int, error) {
+	q := newPruneQuery(opts)
+	var res struct {
+		Pruned int `json:"pruned"`
+	}
+	return res.Pruned, c.RequestJSON(ctx, "GET", c.BaseURL+EndpointGuildPrune(gid)+q.Encode(), nil, &res)
+}
+
+func (c *client) GuildPrune(ctx context.Context, gid string, opts ...PruneOpt) (int, error) {
+	q := newPruneQuery(opts)
+	data, err := json.Marshal(q)
+	if err != nil {
+		return 0, err
+	}
+	var res struct {
+		Pruned *int `json:"pruned"`
+	}
+	if err := c.RequestJSON(ctx, "POST", c.BaseURL+EndpointGuildPrune(gid), data, &res,
+		WithAuditLogReason(q.Reason)); err != nil {
+		return 0, err
+	}
+	if res.Pruned == nil {
+		return 0, nil
+	}
+	return *res.Pruned, nil
+}
+
+// Returns a member's user ID.
+func memberID(m *discordgo.Member) string {
+	return m.User.ID
+}
@@ -0,0 +1,156 @@
+package dgo2poc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientGuildMember(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"user":{"id":"5678"},"nick":"Nick"}`)
+	defer srv.Close()
+
+	member, err := cl.GuildMember(context.Background(), "1234", "5678")
+	require.NoError(t, err)
+	assert.Equal(t, "GET", rec.Last().Method)
+	assert.Equal(t, "/guilds/1234/members/5678", rec.Last().Path)
+	assert.Equal(t, "Nick", member.Nick)
+}
+
+func TestClientGuildMembers(t *testing.T) {
+	// 1500 members, with IDs 1-1500.
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/guilds/1234/members", req.URL.Path)
+		after, _ := strconv.Atoi(req.URL.Query().Get("after"))
+		limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+		var members []*discordgo.Member
+		for id := after + 1; id <= 1500 && len(members) < limit; id++ {
+			members = append(members, &discordgo.Member{User: &discordgo.User{ID: strconv.Itoa(id)}})
+		}
+		require.NoError(t, json.NewEncoder(rw).Encode(members))
+	}))
+	defer srv.Close()
+
+	members, err := newTestClient(srv).GuildMembers(context.Background(), "1234").All()
+	require.NoError(t, err)
+	require.Len(t, members, 1500)
+	assert.Equal(t, "1", members[0].User.ID)
+	assert.Equal(t, "1500", members[1499].User.ID)
+}
+
+func TestClientGuildMemberSearch(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `[{"user":{"id":"5678"}}]`)
+	defer srv.Close()
+
+	members, err := cl.GuildMemberSearch(context.Background(), "1234", "jo hn", 10)
+	require.NoError(t, err)
+	assert.Equal(t, "/guilds/1234/members/search", rec.Last().Path)
+	assert.Equal(t, "limit=10&query=jo+hn", rec.Last().Query)
+	assert.Len(t, members, 1)
+}
+
+func TestClientGuildMemberEdit(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"user":{"id":"5678"}}`)
+	defer srv.Close()
+	ctx := context.Background()
+
+	until := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	_, err := cl.GuildMemberEdit(ctx, "1234", "5678",
+		MemberWithNick("Nick"),
+		MemberWithRoles("1", "2"),
+		MemberWithMute(true),
+		MemberWithTimeout(until),
+		MemberWithReason("being rude"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "PATCH", rec.Last().Method)
+	assert.Equal(t, "/guilds/1234/members/5678", rec.Last().Path)
+	assert.Equal(t, "being%20rude", rec.Last().Header.Get("X-Audit-Log-Reason"))
+	assert.JSONEq(t, `{
+		"nick": "Nick",
+		"roles": ["1", "2"],
+		"mute": true,
+		"communication_disabled_until": "2023-01-02T03:04:05Z"
+	}`, rec.Last().Body)
+
+	t.Run("Reset", func(t *testing.T) {
+		_, err := cl.GuildMemberEdit(ctx, "1234", "5678",
+			MemberWithNick(""),
+			MemberWithRoles(),
+			MemberWithVoiceChannel(""),
+			MemberWithTimeout(time.Time{}),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "", rec.Last().Header.Get("X-Audit-Log-Reason"))
+		assert.JSONEq(t, `{
+			"nick": null,
+			"roles": [],
+			"channel_id": null,
+			"communication_disabled_until": null
+		}`, rec.Last().Body)
+	})
+}
+
+func TestClientGuildMemberRoles(t *testing.T) {
+	srv, cl, rec := newTestServer(t, "")
+	defer srv.Close()
+	ctx := context.Background()
+
+	require.NoError(t, cl.GuildMemberRoleAdd(ctx, "1234", "5678", "9999", "promoted"))
+	assert.Equal(t, "PUT", rec.Last().Method)
+	assert.Equal(t, "/guilds/1234/members/5678/roles/9999", rec.Last().Path)
+	assert.Equal(t, "promoted", rec.Last().Header.Get("X-Audit-Log-Reason"))
+
+	require.NoError(t, cl.GuildMemberRoleRemove(ctx, "1234", "5678", "9999", ""))
+	assert.Equal(t, "DELETE", rec.Last().Method)
+	assert.Equal(t, "/guilds/1234/members/5678/roles/9999", rec.Last().Path)
+	assert.Equal(t, "", rec.Last().Header.Get("X-Audit-Log-Reason"))
+}
+
+func TestClientGuildMemberKick(t *testing.T) {
+	srv, cl, rec := newTestServer(t, "")
+	defer srv.Close()
+
+	require.NoError(t, cl.GuildMemberKick(context.Background(), "1234", "5678", "spam"))
+	assert.Equal(t, "DELETE", rec.Last().Method)
+	assert.Equal(t, "/guilds/1234/members/5678", rec.Last().Path)
+	assert.Equal(t, "spam", rec.Last().Header.Get("X-Audit-Log-Reason"))
+}
+
+func TestClientGuildPrune(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"pruned":3}`)
+	defer srv.Close()
+	ctx := context.Background()
+
+	n, err := cl.GuildPruneCount(ctx, "1234", PruneDays(30), PruneIncludeRoles("1", "2"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, "GET", rec.Last().Method)
+	assert.Equal(t, "/guilds/1234/prune", rec.Last().Path)
+	assert.Equal(t, "days=30&include_roles=1&include_roles=2", rec.Last().Query)
+
+	n, err = cl.GuildPrune(ctx, "1234", PruneDays(30), PruneWithReason("cleanup"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, "POST", rec.Last().Method)
+	assert.Equal(t, "cleanup", rec.Last().Header.Get("X-Audit-Log-Reason"))
+	assert.JSONEq(t, `{"days":30,"compute_prune_count":true}`, rec.Last().Body)
+
+	t.Run("WithoutCount", func(t *testing.T) {
+		srv, cl, rec := newTestServer(t, `{"pruned":null}`)
+		defer srv.Close()
+
+		n, err := cl.GuildPrune(ctx, "1234", PruneWithoutCount())
+		require.NoError(t, err)
+		assert.Equal(t, 0, n)
+		assert.JSONEq(t, `{"compute_prune_count":false}`, rec.Last().Body)
+	})
+}
@@ -1,17 +1,386 @@
 package dgo2poc
 
 import (
+	"io"
+	"math/rand"
+	"net/url"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
 	"github.com/bwmarrin/discordgo"
 )
 
 // ...Message type definition would go here...
 
+// A message to send; see Client.ChannelMessageCreate().
+type MessageSend struct {
+	discordgo.MessageSend
+
+	// Discord deduplicates messages sent with the same nonce if EnforceNonce is set.
+	// Client.ChannelMessageCreate() generates one if none is given.
+	Nonce        string `json:"nonce,omitempty"`
+	EnforceNonce bool   `json:"enforce_nonce,omitempty"`
+
+	// Message flags, eg. discordgo.MessageFlagsSuppressEmbeds.
+	Flags discordgo.MessageFlags `json:"flags,omitempty"`
+
+	// Files to upload; shadows discordgo's version, which lacks descriptions and spoilers.
+	// Attachment metadata for each file is generated when the message is sent.
+	Files       []*File              `json:"-"`
+	Attachments []*MessageAttachment `json:"attachments,omitempty"`
+
+	// Webhooks only: override the webhook's name and avatar, or create a post in a forum channel.
+	Username   string `json:"username,omitempty"`
+	AvatarURL  string `json:"avatar_url,omitempty"`
+	ThreadName string `json:"thread_name,omitempty"`
+}
+
+// Returns a MessageSend with the given content and options applied.
+func newMessageSend(content string, opts []SendOpt) MessageSend {
+	send := MessageSend{MessageSend: discordgo.MessageSend{Content: content}}
+	for _, opt := range opts {
+		opt(&send)
+	}
+	return send
+}
+
+// Checks the message's content, embeds and components against Discord's limits.
+func (send MessageSend) validate() error {
+	if n := utf8.RuneCountInString(send.Content); n > MessageMaxLength {
+		return &ContentLengthError{Length: n, Max: MessageMaxLength}
+	}
+	if err := ValidateEmbeds(send.Embeds...); err != nil {
+		return err
+	}
+	return ValidateComponents(send.Components...)
+}
+
+// A file to upload with a message; see SendWithFile().
+type File struct {
+	Name        string
+	ContentType string // Guessed from the name if empty.
+	Description string // Alt text for the file.
+	Spoiler     bool   // Hide the file behind a spoiler.
+
+	// Files are streamed from their readers, not buffered in memory. If a request needs to be
+	// retried, it can only do so if all of its files' readers are also io.Seekers.
+	Reader io.Reader
+}
+
+// Returns the filename the file is uploaded as.
+func (f *File) filename() string {
+	if f.Spoiler {
+		return "SPOILER_" + f.Name
+	}
+	return f.Name
+}
+
+// Attachment metadata in a message payload. For new files, the ID is the file's index.
+type MessageAttachment struct {
+	ID          string `json:"id"`
+	Filename    string `json:"filename,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Options for SendWithFile().
+type FileOpt func(f *File)
+
+// Set a file's content type, instead of guessing it from the filename.
+func FileWithContentType(ct string) FileOpt {
+	return FileOpt(func(f *File) {
+		f.ContentType = ct
+	})
+}
+
+// Set a file's description (alt text).
+func FileWithDescription(desc string) FileOpt {
+	return FileOpt(func(f *File) {
+		f.Description = desc
+	})
+}
+
+// Mark a file as a spoiler.
+func FileAsSpoiler() FileOpt {
+	return FileOpt(func(f *File) {
+		f.Spoiler = true
+	})
+}
+
 // Options for Client.ChannelMessageSend().
-type SendOpt func(send *discordgo.MessageSend)
+type SendOpt func(send *MessageSend)
 
-// Attach a file with a message.
+// Attach an embed with a message, eg. from NewEmbed(). May be given multiple times, to attach
+// up to MessageMaxEmbeds embeds; they're checked with ValidateEmbeds() when the message is sent.
 func SendWithEmbed(embed *discordgo.MessageEmbed) SendOpt {
-	return SendOpt(func(send *discordgo.MessageSend) {
-		send.Embed = embed
+	return SendWithEmbeds(embed)
+}
+
+// Attach multiple embeds with a message; see SendWithEmbed().
+func SendWithEmbeds(embeds ...*discordgo.MessageEmbed) SendOpt {
+	return SendOpt(func(send *MessageSend) {
+		send.Embeds = append(send.Embeds, embeds...)
+	})
+}
+
+// Attach components to a message, eg. ActionRow(Button(...)). May be given multiple times.
+func SendWithComponents(components ...discordgo.MessageComponent) SendOpt {
+	return SendOpt(func(send *MessageSend) {
+		send.Components = append(send.Components, components...)
+	})
+}
+
+// Attach a file with a message. The file is streamed from the reader when the message is sent.
+// May be given multiple times, to attach multiple files.
+func SendWithFile(name string, r io.Reader, opts ...FileOpt) SendOpt {
+	f := &File{Name: name, Reader: r}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return SendOpt(func(send *MessageSend) {
+		send.Files = append(send.Files, f)
+	})
+}
+
+// Set flags on a message, eg. discordgo.MessageFlagsSuppressEmbeds.
+func SendWithFlags(flags discordgo.MessageFlags) SendOpt {
+	return SendOpt(func(send *MessageSend) {
+		send.Flags |= flags
+	})
+}
+
+// Send a message with a nonce, for deduplication. If enforce is true, Discord won't create a second
+// message with the same nonce within a few minutes, which also makes it safe to retry.
+func SendWithNonce(nonce string, enforce bool) SendOpt {
+	return SendOpt(func(send *MessageSend) {
+		send.Nonce = nonce
+		send.EnforceNonce = enforce
 	})
 }
+
+// Override the name a webhook message is sent under. Only works with WebhookClient.Execute().
+func SendWithUsername(name string) SendOpt {
+	return SendOpt(func(send *MessageSend) {
+		send.Username = name
+	})
+}
+
+// Override the avatar a webhook message is sent with. Only works with WebhookClient.Execute().
+func SendWithAvatarURL(url string) SendOpt {
+	return SendOpt(func(send *MessageSend) {
+		send.AvatarURL = url
+	})
+}
+
+// Create a post with the given title in a forum channel, starting with this message.
+// Only works with WebhookClient.Execute(), for webhooks in forum channels.
+func SendWithThreadName(name string) SendOpt {
+	return SendOpt(func(send *MessageSend) {
+		send.ThreadName = name
+	})
+}
+
+// An edit to a message; see Client.ChannelMessageEdit(). Nil fields are left unchanged.
+type MessageEdit struct {
+	Content    *string                       `json:"content,omitempty"`
+	Embeds     *[]*discordgo.MessageEmbed    `json:"embeds,omitempty"`
+	Flags      *discordgo.MessageFlags       `json:"flags,omitempty"`
+	Components *[]discordgo.MessageComponent `json:"components,omitempty"`
+
+	// Files to upload. If any are given, only attachments listed in Attachments are kept.
+	Files       []*File               `json:"-"`
+	Attachments *[]*MessageAttachment `json:"attachments,omitempty"`
+}
+
+// Checks the edit's embeds and components against Discord's limits.
+func (edit MessageEdit) validate() error {
+	if edit.Embeds != nil {
+		if err := ValidateEmbeds(*edit.Embeds...); err != nil {
+			return err
+		}
+	}
+	if edit.Components != nil {
+		return ValidateComponents(*edit.Components...)
+	}
+	return nil
+}
+
+// Options for Client.ChannelMessageEdit().
+type EditOpt func(edit *MessageEdit)
+
+// Returns a MessageEdit with the given options applied.
+func newMessageEdit(opts []EditOpt) MessageEdit {
+	var edit MessageEdit
+	for _, opt := range opts {
+		opt(&edit)
+	}
+
+	// Attachments not listed in an edit are removed, so new files must be listed explicitly.
+	if len(edit.Files) > 0 {
+		var atts []*MessageAttachment
+		if edit.Attachments != nil {
+			atts = *edit.Attachments
+		}
+		atts = append(atts, fileAttachments(edit.Files)...)
+		edit.Attachments = &atts
+	}
+	return edit
+}
+
+// Change a message's content.
+func EditWithContent(content string) EditOpt {
+	return EditOpt(func(edit *MessageEdit) {
+		edit.Content = &content
+	})
+}
+
+// Replace a message's embeds. May be given multiple times, to attach multiple embeds.
+func EditWithEmbed(embed *discordgo.MessageEmbed) EditOpt {
+	return EditOpt(func(edit *MessageEdit) {
+		var embeds []*discordgo.MessageEmbed
+		if edit.Embeds != nil {
+			embeds = *edit.Embeds
+		}
+		embeds = append(embeds, embed)
+		edit.Embeds = &embeds
+	})
+}
+
+// Remove all embeds from a message.
+func EditWithoutEmbeds() EditOpt {
+	return EditOpt(func(edit *MessageEdit) {
+		edit.Embeds = &[]*discordgo.MessageEmbed{}
+	})
+}
+
+// Replace a message's components. May be given multiple times, to attach multiple action rows.
+func EditWithComponents(components ...discordgo.MessageComponent) EditOpt {
+	return EditOpt(func(edit *MessageEdit) {
+		var all []discordgo.MessageComponent
+		if edit.Components != nil {
+			all = *edit.Components
+		}
+		all = append(all, components...)
+		edit.Components = &all
+	})
+}
+
+// Remove all components from a message.
+func EditWithoutComponents() EditOpt {
+	return EditOpt(func(edit *MessageEdit) {
+		edit.Components = &[]discordgo.MessageComponent{}
+	})
+}
+
+// Attach a file to a message. Existing attachments are removed, unless kept with EditKeepAttachments().
+func EditWithFile(name string, r io.Reader, opts ...FileOpt) EditOpt {
+	f := &File{Name: name, Reader: r}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return EditOpt(func(edit *MessageEdit) {
+		edit.Files = append(edit.Files, f)
+	})
+}
+
+// Keep the given attachments, removing all others. Call with no IDs to remove all attachments.
+func EditKeepAttachments(ids ...string) EditOpt {
+	return EditOpt(func(edit *MessageEdit) {
+		atts := make([]*MessageAttachment, len(ids))
+		for i, id := range ids {
+			atts[i] = &MessageAttachment{ID: id}
+		}
+		edit.Attachments = &atts
+	})
+}
+
+// Replace a message's flags, eg. to suppress embeds.
+func EditWithFlags(flags discordgo.MessageFlags) EditOpt {
+	return EditOpt(func(edit *MessageEdit) {
+		edit.Flags = &flags
+	})
+}
+
+// Limits for Client.ChannelMessagesBulkDelete(); older or more messages are handled automatically.
+const (
+	BulkDeleteMaxAge      = 14 * 24 * time.Hour
+	BulkDeleteMaxMessages = 100
+)
+
+// Result of deleting a single message; see Client.ChannelMessagesBulkDelete().
+type DeleteResult struct {
+	ID  string
+	Err error // nil if the message was deleted
+}
+
+// Query for Client.ChannelMessages(). Only one of Before, After and Around may be set.
+// Limit is the max number of messages to return in total.
+type MessagesQuery struct {
+	Before string
+	After  string
+	Around string
+	Limit  int
+}
+
+// Returns the query as a query string, including the leading "?", or "" if it's empty.
+func (q MessagesQuery) Encode() string {
+	v := url.Values{}
+	if q.Before != "" {
+		v.Set("before", q.Before)
+	}
+	if q.After != "" {
+		v.Set("after", q.After)
+	}
+	if q.Around != "" {
+		v.Set("around", q.Around)
+	}
+	if q.Limit != 0 {
+		v.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if len(v) == 0 {
+		return ""
+	}
+	return "?" + v.Encode()
+}
+
+// Options for Client.ChannelMessages().
+type MessagesOpt func(q *MessagesQuery)
+
+// Return messages before the given message ID.
+func MessagesBefore(id string) MessagesOpt {
+	return MessagesOpt(func(q *MessagesQuery) {
+		q.Before = id
+	})
+}
+
+// Return messages after the given message ID.
+func MessagesAfter(id string) MessagesOpt {
+	return MessagesOpt(func(q *MessagesQuery) {
+		q.After = id
+	})
+}
+
+// Return messages around the given message ID. These can't be paged through, so at most 100
+// messages are returned.
+func MessagesAround(id string) MessagesOpt {
+	return MessagesOpt(func(q *MessagesQuery) {
+		q.Around = id
+	})
+}
+
+// Return at most n messages in total. By default, there's no limit.
+func MessagesLimit(n int) MessagesOpt {
+	return MessagesOpt(func(q *MessagesQuery) {
+		q.Limit = n
+	})
+}
+
+// Returns a random nonce. Nonces may be up to 25 characters long.
+func newNonce() string {
+	return strconv.FormatUint(rand.Uint64(), 36)
+}
+
+// Returns a message's ID.
+func messageID(msg *discordgo.Message) string {
+	return msg.ID
+}
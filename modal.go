@@ -0,0 +1,274 @@
+package dgo2poc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pkg/errors"
+)
+
+// Modal limits: a modal can have up to 5 text inputs, each holding up to 4000 characters.
+const (
+	ModalMaxInputs     = 5
+	TextInputMaxLength = 4000
+)
+
+// Options for TextInput().
+type TextInputOpt func(ti *discordgo.TextInput)
+
+// Show a multi-line text area instead of a single line.
+func TextInputParagraph() TextInputOpt {
+	return TextInputOpt(func(ti *discordgo.TextInput) {
+		ti.Style = discordgo.TextInputParagraph
+	})
+}
+
+// Require the input to be filled in.
+func TextInputRequired() TextInputOpt {
+	return TextInputOpt(func(ti *discordgo.TextInput) {
+		ti.Required = true
+	})
+}
+
+// Limit the length of the input, in characters. A max of 0 means TextInputMaxLength.
+func TextInputLength(min, max int) TextInputOpt {
+	return TextInputOpt(func(ti *discordgo.TextInput) {
+		ti.MinLength = min
+		ti.MaxLength = max
+	})
+}
+
+// Show placeholder text when the input is empty.
+func TextInputWithPlaceholder(text string) TextInputOpt {
+	return TextInputOpt(func(ti *discordgo.TextInput) {
+		ti.Placeholder = text
+	})
+}
+
+// Pre-fill the input with a value.
+func TextInputWithValue(value string) TextInputOpt {
+	return TextInputOpt(func(ti *discordgo.TextInput) {
+		ti.Value = value
+	})
+}
+
+// Returns a text input for a modal, in its own action row; see Client.InteractionRespondModal().
+func TextInput(customID, label string, opts ...TextInputOpt) discordgo.ActionsRow {
+	ti := discordgo.TextInput{CustomID: customID, Label: label, Style: discordgo.TextInputShort}
+	for _, opt := range opts {
+		opt(&ti)
+	}
+	return ActionRow(ti)
+}
+
+// Opens a modal in response to an interaction. The modal's custom ID includes the interaction's ID,
+// which is passed to the handler registered with HandleModal() as ModalInteraction.OpenerID.
+// The inputs can be created with TextInput(), or from a form struct with ModalInputs().
+func OpenModal(ctx context.Context, i *discordgo.Interaction, name, title string, inputs ...discordgo.MessageComponent) error {
+	return GetClient(ctx).InteractionRespondModal(ctx, i, name+":"+i.ID, title, inputs...)
+}
+
+// A submitted modal; see HandleModal().
+type ModalInteraction struct {
+	*discordgo.Interaction
+
+	// The modal's data; shadows Interaction.Data.
+	Data discordgo.ModalSubmitInteractionData
+
+	// ID of the interaction the modal was opened in response to, if it was opened with OpenModal().
+	OpenerID string
+}
+
+// Returns the value of the text input with the given custom ID, or "" if there's none.
+func (m *ModalInteraction) Value(customID string) string {
+	return modalValues(m.Data.Components)[customID]
+}
+
+// Registers a handler for modals opened with OpenModal() under the given name. Submitted values are
+// bound into T, a struct with modal tags, and validated against them; submissions that fail
+// validation are rejected with a *ModalValidationError. The same struct can be used to create the
+// modal's inputs with ModalInputs():
+//
+//	type Feedback struct {
+//		Subject string `modal:"subject,required" label:"Subject" max:"100"`
+//		Body    string `modal:"body,paragraph" label:"Details" placeholder:"What happened?" min:"10"`
+//	}
+//
+//	HandleModal(r, "feedback", func(ctx context.Context, m *ModalInteraction, form *Feedback) error {
+//		return GetClient(ctx).InteractionRespond(ctx, m.Interaction, "Thanks!")
+//	})
+//	OpenModal(ctx, i, "feedback", "Send feedback", ModalInputs(&Feedback{})...)
+//
+// Panics if T's tags are invalid, or the name already has a handler.
+func HandleModal[T any](r *Router, name string, fn func(ctx context.Context, m *ModalInteraction, form *T) error) {
+	spec, err := parseModalForm(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		panic(errors.Wrap(err, name))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.modals == nil {
+		r.modals = make(map[string]modalHandler)
+	}
+	if r.modals[name] != nil {
+		panic(errors.Errorf("modal %q: already registered", name))
+	}
+	r.modals[name] = func(ctx context.Context, m *ModalInteraction) error {
+		var form T
+		if err := spec.Bind(&form, modalValues(m.Data.Components)); err != nil {
+			return errors.Wrap(err, name)
+		}
+		return fn(ctx, m, &form)
+	}
+}
+
+// Returns text inputs for a form struct with modal tags, pre-filled with its values; see HandleModal().
+// Panics if the struct's tags are invalid.
+func ModalInputs(form interface{}) []discordgo.MessageComponent {
+	rv := reflect.Indirect(reflect.ValueOf(form))
+	spec, err := parseModalForm(rv.Type())
+	if err != nil {
+		panic(err)
+	}
+	inputs := make([]discordgo.MessageComponent, len(spec.Fields))
+	for i, f := range spec.Fields {
+		ti := f.Input
+		ti.Value = rv.Field(f.Index).String()
+		inputs[i] = ActionRow(ti)
+	}
+	return inputs
+}
+
+// Handles a submitted modal.
+type modalHandler func(ctx context.Context, m *ModalInteraction) error
+
+// Returns the values of a submitted modal's text inputs, by custom ID.
+func modalValues(components []discordgo.MessageComponent) map[string]string {
+	values := make(map[string]string)
+	for _, c := range components {
+		switch c := c.(type) {
+		case *discordgo.ActionsRow:
+			for k, v := range modalValues(c.Components) {
+				values[k] = v
+			}
+		case *discordgo.TextInput:
+			values[c.CustomID] = c.Value
+		}
+	}
+	return values
+}
+
+// A struct field bound to a text input.
+type modalFormField struct {
+	Index int
+	Input discordgo.TextInput
+}
+
+// Describes how a struct's fields are bound to a modal's text inputs.
+type modalFormSpec struct {
+	Fields []modalFormField
+}
+
+// Parses a struct's modal tags. Each exported string field with a "modal" tag becomes a text input:
+//
+//	Name string `modal:"name,required" label:"Your name" placeholder:"Jane Doe" min:"2" max:"32"`
+//	Bio  string `modal:"bio,paragraph" label:"About you"`
+func parseModalForm(t reflect.Type) (*modalFormSpec, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, errors.Errorf("modal forms must be structs, not %s", t)
+	}
+	spec := &modalFormSpec{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("modal")
+		if !ok || !f.IsExported() {
+			continue
+		}
+		if f.Type.Kind() != reflect.String {
+			return nil, errors.Errorf("%s.%s: unsupported type: %s", t.Name(), f.Name, f.Type)
+		}
+		ti, err := parseModalInput(f, tag)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s.%s", t.Name(), f.Name)
+		}
+		spec.Fields = append(spec.Fields, modalFormField{Index: i, Input: ti})
+	}
+	if len(spec.Fields) > ModalMaxInputs {
+		return nil, errors.Errorf("%s: modals can have at most %d inputs", t.Name(), ModalMaxInputs)
+	}
+	return spec, nil
+}
+
+// Parses a single field's modal tags.
+func parseModalInput(f reflect.StructField, tag string) (discordgo.TextInput, error) {
+	id, flags, _ := strings.Cut(tag, ",")
+	if id == "" {
+		id = strings.ToLower(f.Name)
+	}
+	ti := discordgo.TextInput{
+		CustomID:    id,
+		Label:       f.Tag.Get("label"),
+		Style:       discordgo.TextInputShort,
+		Placeholder: f.Tag.Get("placeholder"),
+	}
+	if ti.Label == "" {
+		ti.Label = f.Name
+	}
+	for _, flag := range strings.Split(flags, ",") {
+		switch flag {
+		case "":
+		case "required":
+			ti.Required = true
+		case "paragraph":
+			ti.Style = discordgo.TextInputParagraph
+		default:
+			return ti, errors.Errorf("unknown flag: %s", flag)
+		}
+	}
+	for _, lim := range []struct {
+		Tag string
+		Dst *int
+	}{{"min", &ti.MinLength}, {"max", &ti.MaxLength}} {
+		s, ok := f.Tag.Lookup(lim.Tag)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return ti, errors.Wrap(err, lim.Tag)
+		}
+		*lim.Dst = n
+	}
+	return ti, nil
+}
+
+// Binds submitted values to a pointer to a struct of the spec's type, validating them.
+func (spec *modalFormSpec) Bind(ptr interface{}, values map[string]string) error {
+	v := reflect.ValueOf(ptr).Elem()
+	var verr ModalValidationError
+	for _, f := range spec.Fields {
+		value := values[f.Input.CustomID]
+		n := utf8.RuneCountInString(value)
+		switch {
+		case value == "" && f.Input.Required:
+			verr.Errors = append(verr.Errors, FieldError{Field: f.Input.CustomID, Code: "REQUIRED", Message: "is required"})
+		case value != "" && n < f.Input.MinLength:
+			verr.Errors = append(verr.Errors, FieldError{Field: f.Input.CustomID, Code: "MIN_LENGTH",
+				Message: fmt.Sprintf("must be at least %d characters long", f.Input.MinLength)})
+		case f.Input.MaxLength > 0 && n > f.Input.MaxLength:
+			verr.Errors = append(verr.Errors, FieldError{Field: f.Input.CustomID, Code: "MAX_LENGTH",
+				Message: fmt.Sprintf("must be at most %d characters long", f.Input.MaxLength)})
+		}
+		v.Field(f.Index).SetString(value)
+	}
+	if len(verr.Errors) > 0 {
+		return &verr
+	}
+	return nil
+}
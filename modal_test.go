@@ -0,0 +1,119 @@
+package dgo2poc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testFeedbackForm struct {
+	Subject string `modal:"subject,required" label:"Subject" max:"10"`
+	Body    string `modal:"body,paragraph" label:"Details" placeholder:"What happened?" min:"5"`
+	Ignored string
+}
+
+func TestTextInput(t *testing.T) {
+	data, err := json.Marshal(TextInput("name", "Your name", TextInputRequired(), TextInputLength(2, 32),
+		TextInputWithPlaceholder("Jane"), TextInputWithValue("Meow")))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":1,"components":[{"type":4,"custom_id":"name","label":"Your name","style":1,
+		"placeholder":"Jane","value":"Meow","required":true,"min_length":2,"max_length":32}]}`, string(data))
+
+	data, err = json.Marshal(TextInput("bio", "About you", TextInputParagraph()))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":1,"components":[{"type":4,"custom_id":"bio","label":"About you","style":2,"required":false}]}`, string(data))
+}
+
+func TestModalInputs(t *testing.T) {
+	data, err := json.Marshal(ModalInputs(&testFeedbackForm{Subject: "Hi"}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"type":1,"components":[{"type":4,"custom_id":"subject","label":"Subject","style":1,"value":"Hi","required":true,"max_length":10}]},
+		{"type":1,"components":[{"type":4,"custom_id":"body","label":"Details","style":2,"placeholder":"What happened?","required":false,"min_length":5}]}
+	]`, string(data))
+
+	assert.Panics(t, func() {
+		ModalInputs(struct {
+			X int `modal:"x"`
+		}{})
+	})
+	assert.Panics(t, func() {
+		ModalInputs(struct {
+			X string `modal:"x,big"`
+		}{})
+	})
+}
+
+func TestRouterModals(t *testing.T) {
+	srv, cl, rec := newTestServer(t, "")
+	defer srv.Close()
+	ctx := withClient(context.Background(), cl)
+
+	r := NewRouter()
+	var got *testFeedbackForm
+	var gotModal *ModalInteraction
+	HandleModal(r, "feedback", func(ctx context.Context, m *ModalInteraction, form *testFeedbackForm) error {
+		got, gotModal = form, m
+		return nil
+	})
+	assert.Panics(t, func() {
+		HandleModal(r, "feedback", func(context.Context, *ModalInteraction, *testFeedbackForm) error { return nil })
+	})
+
+	opener := newTestInteraction(discordgo.InteractionApplicationCommand, time.Now())
+	require.NoError(t, OpenModal(ctx, opener, "feedback", "Send feedback", ModalInputs(&testFeedbackForm{})...))
+	assert.Equal(t, "/interactions/"+opener.ID+"/tok/callback", rec.Last().Path)
+	assert.Contains(t, rec.Last().Body, `"type":9,"data":{"custom_id":"feedback:`+opener.ID+`","title":"Send feedback"`)
+
+	submit := func(customID, subject, body string) *discordgo.Interaction {
+		return newTestInteractionWithData(t, discordgo.InteractionModalSubmit, time.Now(), `{"custom_id":"`+customID+`","components":[
+			{"type":1,"components":[{"type":4,"custom_id":"subject","value":"`+subject+`"}]},
+			{"type":1,"components":[{"type":4,"custom_id":"body","value":"`+body+`"}]}
+		]}`)
+	}
+
+	t.Run("Submit", func(t *testing.T) {
+		require.NoError(t, r.Dispatch(ctx, submit("feedback:"+opener.ID, "Bug", "It's broken")))
+		assert.Equal(t, &testFeedbackForm{Subject: "Bug", Body: "It's broken"}, got)
+		assert.Equal(t, opener.ID, gotModal.OpenerID)
+		assert.Equal(t, "Bug", gotModal.Value("subject"))
+	})
+
+	t.Run("NoOpener", func(t *testing.T) {
+		require.NoError(t, r.Dispatch(ctx, submit("feedback", "Bug", "")))
+		assert.Equal(t, "", gotModal.OpenerID)
+		assert.Equal(t, &testFeedbackForm{Subject: "Bug"}, got)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		got = nil
+		err := r.Dispatch(ctx, submit("feedback:1", "", "短い"))
+		var verr *ModalValidationError
+		require.True(t, errors.As(err, &verr), "%v", err)
+		assert.Equal(t, []FieldError{
+			{Field: "subject", Code: "REQUIRED", Message: "is required"},
+			{Field: "body", Code: "MIN_LENGTH", Message: "must be at least 5 characters long"},
+		}, verr.Errors)
+		assert.EqualError(t, err, "feedback: invalid modal submission (subject: is required; body: must be at least 5 characters long)")
+		assert.Nil(t, got)
+
+		err = r.Dispatch(ctx, submit("feedback:1", strings.Repeat("é", 11), "Long enough"))
+		require.True(t, errors.As(err, &verr), "%v", err)
+		assert.Equal(t, []FieldError{
+			{Field: "subject", Code: "MAX_LENGTH", Message: "must be at most 10 characters long"},
+		}, verr.Errors)
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		err := r.Dispatch(ctx, submit("survey:1", "", ""))
+		assert.True(t, errors.Is(err, ErrUnknownModal), "%v", err)
+		assert.EqualError(t, err, "survey:1: unknown modal")
+	})
+}
@@ -0,0 +1,145 @@
+package dgo2poc
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// A multipart/form-data body for a message with files: a payload_json part, and one per file.
+type multipartBody struct {
+	boundary string
+	payload  []byte
+	files    []*File
+	offsets  []int64       // where each file's reader started, or -1 if it can't seek
+	done     chan struct{} // closed when the last writer is done
+}
+
+// Returns attachment metadata for files to be uploaded; the ID of each is its index.
+func fileAttachments(files []*File) []*MessageAttachment {
+	atts := make([]*MessageAttachment, len(files))
+	for i, f := range files {
+		atts[i] = &MessageAttachment{
+			ID:          strconv.Itoa(i),
+			Filename:    f.filename(),
+			Description: f.Description,
+		}
+	}
+	return atts
+}
+
+// Creates a multipart body from a JSON payload and a list of files.
+func newMultipartBody(payload []byte, files []*File) *multipartBody {
+	offsets := make([]int64, len(files))
+	for i, f := range files {
+		offsets[i] = -1
+		if seeker, ok := f.Reader.(io.Seeker); ok {
+			if offset, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+				offsets[i] = offset
+			}
+		}
+	}
+	return &multipartBody{
+		boundary: multipart.NewWriter(ioutil.Discard).Boundary(),
+		payload:  payload,
+		files:    files,
+		offsets:  offsets,
+	}
+}
+
+// Returns the Content-Type header for the body.
+func (b *multipartBody) ContentType() string {
+	return "multipart/form-data; boundary=" + b.boundary
+}
+
+// Reports whether the body can be opened more than once, ie. whether all files can be rewound.
+func (b *multipartBody) Reopenable() bool {
+	for _, offset := range b.offsets {
+		if offset < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Opens the body for reading; files are streamed as it's read. Because the files' readers can only
+// be consumed once, it can only be reopened (eg. for retries) if they're all io.Seekers; each is
+// rewound to wherever it was when the body was created.
+func (b *multipartBody) Open() (io.ReadCloser, error) {
+	if b.done != nil {
+		<-b.done
+		for i, f := range b.files {
+			seeker, ok := f.Reader.(io.Seeker)
+			if !ok {
+				return nil, errors.Errorf("can't resend file: %s: reader is not an io.Seeker", f.Name)
+			}
+			if b.offsets[i] < 0 {
+				return nil, errors.Errorf("can't resend file: %s: reader is not seekable", f.Name)
+			}
+			if _, err := seeker.Seek(b.offsets[i], io.SeekStart); err != nil {
+				return nil, errors.Wrapf(err, "can't resend file: %s", f.Name)
+			}
+		}
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	b.done = done
+	go func() {
+		defer close(done)
+		_ = pw.CloseWithError(b.write(pw))
+	}()
+	return pr, nil
+}
+
+func (b *multipartBody) write(w io.Writer) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(b.boundary); err != nil {
+		return err
+	}
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", `form-data; name="payload_json"`)
+	h.Set("Content-Type", "application/json")
+	pw, err := mw.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	if _, err := pw.Write(b.payload); err != nil {
+		return err
+	}
+
+	for i, f := range b.files {
+		ct := f.ContentType
+		if ct == "" {
+			ct = mime.TypeByExtension(filepath.Ext(f.Name))
+		}
+		if ct == "" {
+			ct = "application/octet-stream"
+		}
+
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="files[%d]"; filename="%s"`,
+			i, quoteEscaper.Replace(f.filename())))
+		h.Set("Content-Type", ct)
+		fw, err := mw.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(fw, f.Reader); err != nil {
+			return errors.Wrap(err, f.Name)
+		}
+	}
+
+	return mw.Close()
+}
@@ -0,0 +1,163 @@
+package dgo2poc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelMessageCreateFiles(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/channels/1234/messages", req.URL.Path)
+		mr, err := req.MultipartReader()
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		part, err := mr.NextPart()
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "payload_json", part.FormName())
+		var send MessageSend
+		assert.NoError(t, json.NewDecoder(part).Decode(&send))
+		assert.Equal(t, "hi", send.Content)
+		assert.Equal(t, []*MessageAttachment{
+			{ID: "0", Filename: "report.txt"},
+			{ID: "1", Filename: "SPOILER_cat.png", Description: "a cat"},
+		}, send.Attachments)
+
+		part, err = mr.NextPart()
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "files[0]", part.FormName())
+		assert.Equal(t, "report.txt", part.FileName())
+		assert.Equal(t, "text/plain; charset=utf-8", part.Header.Get("Content-Type"))
+		data, err := ioutil.ReadAll(part)
+		assert.NoError(t, err)
+		assert.Equal(t, "all is well", string(data))
+
+		part, err = mr.NextPart()
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "files[1]", part.FormName())
+		assert.Equal(t, "SPOILER_cat.png", part.FileName())
+		assert.Equal(t, "image/x-cat", part.Header.Get("Content-Type"))
+		data, err = ioutil.ReadAll(part)
+		assert.NoError(t, err)
+		assert.Equal(t, "meow", string(data))
+
+		_, err = mr.NextPart()
+		assert.Equal(t, io.EOF, err)
+
+		_, _ = rw.Write([]byte(`{"id":"5678"}`))
+	}))
+	defer srv.Close()
+
+	// Use a pipe to make sure the file is streamed, rather than read in full up front.
+	pr, pw := io.Pipe()
+	go func() {
+		_, _ = pw.Write([]byte("all is "))
+		_, _ = pw.Write([]byte("well"))
+		_ = pw.Close()
+	}()
+
+	msg, err := newTestClient(srv).ChannelMessageCreate(context.Background(), "1234", "hi",
+		SendWithFile("report.txt", pr),
+		SendWithFile("cat.png", strings.NewReader("meow"),
+			FileWithContentType("image/x-cat"),
+			FileWithDescription("a cat"),
+			FileAsSpoiler(),
+		),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "5678", msg.ID)
+}
+
+func TestChannelMessageCreateFilesRetry(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_ = req.ParseMultipartForm(1024)
+		if atomic.AddInt32(&hits, 1) == 1 {
+			rw.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		assert.Equal(t, "meow", readFormFile(t, req, "files[0]"))
+		_, _ = rw.Write([]byte(`{"id":"5678"}`))
+	}))
+	defer srv.Close()
+	cl := newTestClient(srv, WithRetryPolicy(testRetryPolicy))
+
+	t.Run("Seeker", func(t *testing.T) {
+		_, err := cl.ChannelMessageCreate(context.Background(), "1234", "hi",
+			SendWithFile("cat.txt", bytes.NewReader([]byte("meow"))))
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("Offset", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+		r := strings.NewReader("cat:meow")
+		_, _ = r.Seek(4, io.SeekStart)
+		_, err := cl.ChannelMessageCreate(context.Background(), "1234", "hi", SendWithFile("cat.txt", r))
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("Not Seeker", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+		_, err := cl.ChannelMessageCreate(context.Background(), "1234", "hi",
+			SendWithFile("cat.txt", ioutil.NopCloser(strings.NewReader("meow"))))
+		require.IsType(t, &HTTPError{}, err)
+		assert.Equal(t, http.StatusBadGateway, err.(*HTTPError).StatusCode)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+	})
+}
+
+func TestChannelMessageCreateFilesNoRetry(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_ = req.ParseMultipartForm(1024)
+		atomic.AddInt32(&hits, 1)
+		rw.WriteHeader(http.StatusInternalServerError)
+		_, _ = rw.Write([]byte(`{"code":0,"message":"500: Internal Server Error"}`))
+	}))
+	defer srv.Close()
+	cl := newTestClient(srv, WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Minute, MaxBackoff: time.Minute}))
+
+	// A streamed file can't be sent again, so the 500 is returned right away instead of retried.
+	pr, pw := io.Pipe()
+	go func() {
+		_, _ = pw.Write([]byte("meow"))
+		_ = pw.Close()
+	}()
+	_, err := cl.ChannelMessageCreate(context.Background(), "1234", "hi", SendWithFile("cat.txt", pr))
+	require.IsType(t, &HTTPError{}, err)
+	assert.Equal(t, http.StatusInternalServerError, err.(*HTTPError).StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+}
+
+// Reads a file uploaded with a multipart form. Called from handlers, so it can't use require.
+func readFormFile(t *testing.T, req *http.Request, name string) string {
+	f, _, err := req.FormFile(name)
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	assert.NoError(t, err)
+	return string(data)
+}
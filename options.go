@@ -2,10 +2,34 @@ package dgo2poc
 
 import (
 	"net/http"
+	"net/url"
 )
 
+// Default number of times a rate limited request is retried; see WithRateLimitRetries().
+const DefaultRateLimitRetries = 3
+
 type ReqOptions struct {
 	Request *http.Request
+
+	// Rate limiter consulted before sending the request.
+	RateLimiter RateLimiter
+
+	// Number of times to retry a request that was rate limited.
+	RateLimitRetries int
+
+	// Policy for retrying requests after transient failures.
+	Retry RetryPolicy
+
+	// Whether the request is safe to retry, regardless of its method.
+	Idempotent bool
+}
+
+// Returns whether a request that has already failed n times may be retried.
+func (opts *ReqOptions) canRetry(n int) bool {
+	if n+1 >= opts.Retry.MaxAttempts {
+		return false
+	}
+	return opts.Idempotent || isIdempotent(opts.Request.Method)
 }
 
 // Options can be passed to Client.Request() to customise requests.
@@ -25,3 +49,47 @@ func WithUserAgent(ua string) ReqOption {
 		opts.Request.Header.Set("User-Agent", ua)
 	})
 }
+
+// Use a different RateLimiter than the Client's own. Pass the same RateLimiter to several
+// Clients using the same token, or use NewRemoteRateLimiter(), to share rate limits between them.
+func WithRateLimiter(rl RateLimiter) ReqOption {
+	return ReqOption(func(opts *ReqOptions) {
+		opts.RateLimiter = rl
+	})
+}
+
+// Set how many times a rate limited (429) request is transparently retried, after waiting out the
+// limit. Use 0 for latency-sensitive requests, which will instead return a *RateLimitError.
+func WithRateLimitRetries(n int) ReqOption {
+	return ReqOption(func(opts *ReqOptions) {
+		opts.RateLimitRetries = n
+	})
+}
+
+// Override the policy for retrying requests after network errors or 5xx responses.
+// Pass an empty RetryPolicy{} to disable retries.
+func WithRetryPolicy(p RetryPolicy) ReqOption {
+	return ReqOption(func(opts *ReqOptions) {
+		opts.Retry = p
+	})
+}
+
+// Mark a request as safe to retry, even if its method normally wouldn't be, eg. a POST that
+// carries a nonce Discord uses to deduplicate it.
+func WithIdempotent() ReqOption {
+	return ReqOption(func(opts *ReqOptions) {
+		opts.Idempotent = true
+	})
+}
+
+// Set the reason shown in a guild's audit log for a mutating request. Empty reasons are ignored.
+// Methods without a reason parameter or option can be given one with WithReqOptions(), eg:
+//
+//	cl.GuildRoleDelete(WithReqOptions(ctx, WithAuditLogReason("unused")), gid, rid)
+func WithAuditLogReason(reason string) ReqOption {
+	return ReqOption(func(opts *ReqOptions) {
+		if reason != "" {
+			opts.Request.Header.Set("X-Audit-Log-Reason", url.PathEscape(reason))
+		}
+	})
+}
@@ -20,3 +20,17 @@ func TestWithUserAgent(t *testing.T) {
 	WithUserAgent("test user agent")(&ReqOptions{Request: req})
 	assert.Equal(t, "test user agent", req.Header.Get("User-Agent"))
 }
+
+func TestWithAuditLogReason(t *testing.T) {
+	req, err := http.NewRequest("DELETE", "http://example.com/", nil)
+	assert.NoError(t, err)
+	WithAuditLogReason("spam & ads: 100% 🙃")(&ReqOptions{Request: req})
+	assert.Equal(t, "spam%20&%20ads:%20100%25%20%F0%9F%99%83", req.Header.Get("X-Audit-Log-Reason"))
+
+	t.Run("Empty", func(t *testing.T) {
+		req, err := http.NewRequest("DELETE", "http://example.com/", nil)
+		assert.NoError(t, err)
+		WithAuditLogReason("")(&ReqOptions{Request: req})
+		assert.NotContains(t, req.Header, "X-Audit-Log-Reason")
+	})
+}
@@ -1,7 +1,11 @@
 package dgo2poc
 
 import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
 
 	"golang.org/x/oauth2"
@@ -24,3 +28,70 @@ func requireTestToken(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+// Returns a client pointed at a test server.
+func newTestClient(srv *httptest.Server, opts ...ReqOption) *client {
+	cl := NewClient(BotToken("hi"), opts...).(*client)
+	cl.BaseURL = srv.URL
+	return cl
+}
+
+// A request received by a test server.
+type testRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   string
+}
+
+// Records requests received by a test server.
+type testRecorder struct {
+	mu       sync.Mutex
+	requests []testRequest
+}
+
+// Returns the last recorded request.
+func (r *testRecorder) Last() testRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.requests) == 0 {
+		return testRequest{}
+	}
+	return r.requests[len(r.requests)-1]
+}
+
+// Returns all recorded requests.
+func (r *testRecorder) All() []testRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]testRequest(nil), r.requests...)
+}
+
+// Returns a test server that records requests and responds to all of them with the given body,
+// as well as a client pointed at it.
+func newTestServer(t *testing.T, res string) (*httptest.Server, *client, *testRecorder) {
+	var rec testRecorder
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		rec.mu.Lock()
+		rec.requests = append(rec.requests, testRequest{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Query:  req.URL.RawQuery,
+			Header: req.Header,
+			Body:   string(data),
+		})
+		rec.mu.Unlock()
+
+		if res == "" {
+			rw.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = rw.Write([]byte(res))
+	}))
+	return srv, newTestClient(srv), &rec
+}
@@ -0,0 +1,265 @@
+package dgo2poc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How long to wait before retrying a 429 that doesn't say how long to wait.
+const fallbackRetryAfter = 1 * time.Second
+
+// Top-level resources whose IDs are "major parameters"; these get separate buckets per ID.
+var rateLimitMajors = map[string]bool{
+	"channels": true,
+	"guilds":   true,
+	"webhooks": true,
+}
+
+// A RateLimiter keeps track of Discord's rate limits. A Client consults it before each request,
+// and updates it with the headers of each response.
+//
+// By default, each Client has its own in-memory RateLimiter. To share rate limits across multiple
+// Clients using the same token, pass the same one to each with WithRateLimiter(); to share them
+// across processes, see NewRemoteRateLimiter().
+type RateLimiter interface {
+	// Blocks until a request to the given route may be made, or the context is cancelled.
+	Wait(ctx context.Context, route string) error
+
+	// Updates the limiter with a response's headers.
+	Update(ctx context.Context, route string, h http.Header) error
+}
+
+// In-memory RateLimiter tracking Discord's per-route rate limit buckets.
+type memoryRateLimiter struct {
+	mu      sync.Mutex
+	hashes  map[string]string           // route -> bucket hash
+	buckets map[string]*rateLimitBucket // bucket key -> bucket
+	global  time.Time                   // all requests are paused until this time
+}
+
+// State of a single rate limit bucket.
+type rateLimitBucket struct {
+	Limit     int           // requests per window, 0 if unknown
+	Remaining int           // requests left in the current window
+	Reset     time.Time     // when the current window resets
+	Window    time.Duration // estimated length of a window
+}
+
+// Returns a new, in-memory RateLimiter. It's safe for concurrent use by multiple Clients.
+func NewRateLimiter() RateLimiter {
+	return &memoryRateLimiter{
+		hashes:  make(map[string]string),
+		buckets: make(map[string]*rateLimitBucket),
+	}
+}
+
+func (rl *memoryRateLimiter) Wait(ctx context.Context, route string) error {
+	for {
+		rl.mu.Lock()
+		now := time.Now()
+		delay := rl.global.Sub(now)
+		if delay <= 0 {
+			delay = rl.bucket(route).take(now)
+		}
+		rl.mu.Unlock()
+		if delay <= 0 {
+			return nil
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// Updates the bucket for a route from a response's X-RateLimit-* headers.
+// If the response was a 429, Retry-After is also taken into account.
+func (rl *memoryRateLimiter) Update(ctx context.Context, route string, h http.Header) error {
+	if retryAfter, err := parseSeconds(h.Get("Retry-After")); err == nil {
+		rl.limited(route, h, retryAfter)
+	}
+
+	limit, err := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return nil
+	}
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return nil
+	}
+	resetAfter, err := parseSeconds(h.Get("X-RateLimit-Reset-After"))
+	if err != nil {
+		return nil
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	// Routes that share a bucket hash also share state, as long as their major parameter matches.
+	if hash := h.Get("X-RateLimit-Bucket"); hash != "" && rl.hashes[route] != hash {
+		old := rl.buckets[rl.key(route)]
+		rl.hashes[route] = hash
+		if _, ok := rl.buckets[rl.key(route)]; !ok && old != nil {
+			rl.buckets[rl.key(route)] = old
+		}
+	}
+
+	b := rl.bucket(route)
+	reset := time.Now().Add(resetAfter)
+	if b.Limit == 0 || remaining < b.Remaining {
+		// Responses may arrive out of order, and our own count includes requests still in
+		// flight, so only ever lower the remaining count within a window.
+		b.Remaining = remaining
+	}
+	b.Limit = limit
+	if reset.After(b.Reset) {
+		b.Reset = reset
+	}
+	if resetAfter > b.Window {
+		b.Window = resetAfter
+	}
+	return nil
+}
+
+// Handles a 429 response, pausing either the route's bucket or, for global limits, everything.
+func (rl *memoryRateLimiter) limited(route string, h http.Header, retryAfter time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	until := time.Now().Add(retryAfter)
+	if h.Get("X-RateLimit-Global") == "true" || h.Get("X-RateLimit-Scope") == "global" {
+		if until.After(rl.global) {
+			rl.global = until
+		}
+		return
+	}
+
+	b := rl.bucket(route)
+	if b.Limit == 0 {
+		b.Limit = 1
+	}
+	b.Remaining = 0
+	if until.After(b.Reset) {
+		b.Reset = until
+	}
+}
+
+// Returns the key of the bucket for a route. Must be called with mu held.
+func (rl *memoryRateLimiter) key(route string) string {
+	if hash, ok := rl.hashes[route]; ok {
+		return hash + ":" + rateLimitMajor(route)
+	}
+	return route
+}
+
+// Returns the bucket for a route, creating it if needed. Must be called with mu held.
+func (rl *memoryRateLimiter) bucket(route string) *rateLimitBucket {
+	key := rl.key(route)
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &rateLimitBucket{}
+		rl.buckets[key] = b
+	}
+	return b
+}
+
+// Takes a request from the bucket. If it's exhausted, returns how long to wait instead.
+func (b *rateLimitBucket) take(now time.Time) time.Duration {
+	if b.Limit == 0 {
+		return 0 // We don't know anything about this bucket yet.
+	}
+	if !now.Before(b.Reset) {
+		b.Remaining = b.Limit
+		b.Reset = now.Add(b.Window)
+	}
+	if b.Remaining <= 0 {
+		return b.Reset.Sub(now)
+	}
+	b.Remaining--
+	return 0
+}
+
+// Returns the rate limit route for a request, eg. "GET /channels/1234/messages/:id".
+// IDs are replaced with placeholders, except for major parameters, which get their own buckets.
+func rateLimitRoute(method string, u *url.URL) string {
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	// Strip the API prefix, eg. "/api" or "/api/v6", if present.
+	if len(parts) > 0 && parts[0] == "api" {
+		parts = parts[1:]
+		if len(parts) > 0 && strings.HasPrefix(parts[0], "v") && isNumeric(parts[0][1:]) {
+			parts = parts[1:]
+		}
+	}
+
+	for i := 1; i < len(parts); i++ {
+		switch {
+		case i == 1 && rateLimitMajors[parts[0]]:
+			// Major parameter, eg. a channel or guild ID.
+		case i == 2 && parts[0] == "webhooks":
+			// Webhook tokens are part of the major parameter.
+		case parts[0] == "interactions" && i <= 2:
+			// Interaction IDs and tokens are unique per interaction.
+			parts[i] = ":id"
+		case parts[i-1] == "reactions":
+			// All emoji share a bucket.
+			parts[i] = ":emoji"
+		case isNumeric(parts[i]):
+			parts[i] = ":id"
+		}
+	}
+	return method + " /" + strings.Join(parts, "/")
+}
+
+// Returns the major parameter for a route, eg. "channels/1234", or "" if it has none.
+func rateLimitMajor(route string) string {
+	if idx := strings.IndexByte(route, ' '); idx != -1 {
+		route = route[idx+1:]
+	}
+	parts := strings.Split(strings.Trim(route, "/"), "/")
+	if len(parts) < 2 || !rateLimitMajors[parts[0]] {
+		return ""
+	}
+	if parts[0] == "webhooks" && len(parts) > 2 {
+		return strings.Join(parts[:3], "/")
+	}
+	return strings.Join(parts[:2], "/")
+}
+
+// Parses a duration in (fractional) seconds, as used by rate limit headers.
+func parseSeconds(s string) (time.Duration, error) {
+	secs, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(secs * float64(time.Second)), nil
+}
+
+// Returns whether a string is a non-empty string of digits, eg. a snowflake.
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Sleeps for the given duration, or until the context is cancelled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,136 @@
+package dgo2poc
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// A request to a RateLimiter served by ServeRateLimiter().
+type rateLimitRequest struct {
+	Op     string      `json:"op"` // "wait" or "update"
+	Route  string      `json:"route"`
+	Header http.Header `json:"header,omitempty"`
+}
+
+// A response from a RateLimiter served by ServeRateLimiter().
+type rateLimitResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+// Serves a RateLimiter to other processes until the context is cancelled, eg. over a unix socket.
+// Use NewRemoteRateLimiter() to connect to it, and pass that to each process' Client(s).
+func ServeRateLimiter(ctx context.Context, l net.Listener, rl RateLimiter) error {
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return nil
+			default:
+				return err
+			}
+		}
+		go serveRateLimitConn(ctx, conn, rl)
+	}
+}
+
+// Serves a single request; each connection carries exactly one.
+func serveRateLimitConn(ctx context.Context, conn net.Conn, rl RateLimiter) {
+	defer conn.Close()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var req rateLimitRequest
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+
+	// Clients hang up if they stop waiting, eg. because their context was cancelled.
+	go func() {
+		_, _ = conn.Read(make([]byte, 1))
+		cancel()
+	}()
+
+	var err error
+	switch req.Op {
+	case "wait":
+		err = rl.Wait(ctx, req.Route)
+	case "update":
+		err = rl.Update(ctx, req.Route, req.Header)
+	default:
+		err = errors.Errorf("unknown op: %s", req.Op)
+	}
+
+	var res rateLimitResponse
+	if err != nil {
+		res.Error = err.Error()
+	}
+	_ = json.NewEncoder(conn).Encode(res)
+}
+
+// A RateLimiter backed by one served by ServeRateLimiter().
+type remoteRateLimiter struct {
+	Network string
+	Addr    string
+}
+
+// Returns a RateLimiter that defers to one served by ServeRateLimiter(), eg. in another process.
+// All Clients using the same token should use the same one, else they'll exceed shared limits.
+func NewRemoteRateLimiter(network, addr string) RateLimiter {
+	return &remoteRateLimiter{Network: network, Addr: addr}
+}
+
+func (rl *remoteRateLimiter) Wait(ctx context.Context, route string) error {
+	return rl.call(ctx, rateLimitRequest{Op: "wait", Route: route})
+}
+
+func (rl *remoteRateLimiter) Update(ctx context.Context, route string, h http.Header) error {
+	return rl.call(ctx, rateLimitRequest{Op: "update", Route: route, Header: h})
+}
+
+func (rl *remoteRateLimiter) call(ctx context.Context, req rateLimitRequest) error {
+	conn, err := (&net.Dialer{}).DialContext(ctx, rl.Network, rl.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Hang up if the context is cancelled; this also tells the server to stop waiting.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	var res rateLimitResponse
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return rl.ctxErr(ctx, err)
+	}
+	if err := json.NewDecoder(conn).Decode(&res); err != nil {
+		return rl.ctxErr(ctx, err)
+	}
+	if res.Error != "" {
+		return errors.New(res.Error)
+	}
+	return nil
+}
+
+// Connection errors caused by a cancelled context should be reported as such.
+func (rl *remoteRateLimiter) ctxErr(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+package dgo2poc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteRateLimiter(t *testing.T) {
+	// The server allows one request per 200ms, and counts any that come in faster than that.
+	var mu sync.Mutex
+	var last time.Time
+	var violations int
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		if time.Since(last) < 150*time.Millisecond {
+			violations++
+		}
+		last = time.Now()
+		mu.Unlock()
+
+		rw.Header().Set("X-RateLimit-Bucket", "abcd")
+		rw.Header().Set("X-RateLimit-Limit", "1")
+		rw.Header().Set("X-RateLimit-Remaining", "0")
+		rw.Header().Set("X-RateLimit-Reset-After", "0.2")
+		_, _ = rw.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sock := filepath.Join(t.TempDir(), "ratelimit.sock")
+	l, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	go func() { assert.NoError(t, ServeRateLimiter(ctx, l, NewRateLimiter())) }()
+
+	// Each of these would normally have its own view of the rate limits.
+	clients := []Client{
+		NewClient(BotToken("hi"), WithRateLimiter(NewRemoteRateLimiter("unix", sock))),
+		NewClient(BotToken("hi"), WithRateLimiter(NewRemoteRateLimiter("unix", sock))),
+		NewClient(BotToken("hi"), WithRateLimiter(NewRemoteRateLimiter("unix", sock))),
+	}
+	start := time.Now()
+	for _, cl := range clients {
+		_, err := cl.Request(context.Background(), "GET", srv.URL+"/channels/1234/messages", nil)
+		require.NoError(t, err)
+	}
+	assert.True(t, time.Since(start) >= 300*time.Millisecond, "didn't wait: %s", time.Since(start))
+	mu.Lock()
+	assert.Equal(t, 0, violations)
+	mu.Unlock()
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := clients[0].Request(ctx, "GET", srv.URL+"/channels/1234/messages", nil)
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+}
@@ -0,0 +1,187 @@
+package dgo2poc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitRoute(t *testing.T) {
+	testdata := map[string]string{
+		"/users/@me":                     "GET /users/@me",
+		"/api/users/1234":                "GET /users/:id",
+		"/api/v6/channels/1234/messages": "GET /channels/1234/messages",
+		"/channels/1234/messages/5678":   "GET /channels/1234/messages/:id",
+		"/channels/1234/messages/5678/reactions/%F0%9F%91%8D/@me": "GET /channels/1234/messages/:id/reactions/:emoji/@me",
+		"/guilds/1234/members/5678":                               "GET /guilds/1234/members/:id",
+		"/webhooks/1234/abcd/messages/5678":                       "GET /webhooks/1234/abcd/messages/:id",
+		"/interactions/1234/abcd/callback":                        "GET /interactions/:id/:id/callback",
+	}
+	for path, route := range testdata {
+		t.Run(path, func(t *testing.T) {
+			u, err := url.Parse("https://discordapp.com" + path)
+			require.NoError(t, err)
+			assert.Equal(t, route, rateLimitRoute("GET", u))
+		})
+	}
+}
+
+func TestRateLimitMajor(t *testing.T) {
+	assert.Equal(t, "", rateLimitMajor("GET /users/@me"))
+	assert.Equal(t, "channels/1234", rateLimitMajor("GET /channels/1234/messages/:id"))
+	assert.Equal(t, "guilds/1234", rateLimitMajor("PATCH /guilds/1234"))
+	assert.Equal(t, "webhooks/1234/abcd", rateLimitMajor("POST /webhooks/1234/abcd"))
+}
+
+func TestClientRateLimit(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		rw.Header().Set("X-RateLimit-Bucket", "abcd")
+		rw.Header().Set("X-RateLimit-Limit", "1")
+		rw.Header().Set("X-RateLimit-Remaining", "0")
+		rw.Header().Set("X-RateLimit-Reset-After", "0.2")
+		_, _ = rw.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+	cl := NewClient(BotToken("hi"))
+
+	_, err := cl.Request(context.Background(), "GET", srv.URL+"/channels/1234/messages", nil)
+	require.NoError(t, err)
+
+	t.Run("Wait", func(t *testing.T) {
+		start := time.Now()
+		_, err := cl.Request(context.Background(), "GET", srv.URL+"/channels/1234/messages", nil)
+		require.NoError(t, err)
+		assert.True(t, time.Since(start) >= 150*time.Millisecond, "didn't wait: %s", time.Since(start))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("Other Major", func(t *testing.T) {
+		start := time.Now()
+		_, err := cl.Request(context.Background(), "GET", srv.URL+"/channels/5678/messages", nil)
+		require.NoError(t, err)
+		assert.True(t, time.Since(start) < 150*time.Millisecond, "waited: %s", time.Since(start))
+		assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := cl.Request(ctx, "GET", srv.URL+"/channels/1234/messages", nil)
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+	})
+}
+
+func TestClientRateLimitRetry(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&hits, 1)%2 == 1 {
+			rw.Header().Set("Retry-After", "0.1")
+			rw.Header().Set("X-RateLimit-Scope", "user")
+			rw.WriteHeader(http.StatusTooManyRequests)
+			_, _ = rw.Write([]byte(`{"message":"You are being rate limited.","retry_after":0.1,"global":false}`))
+			return
+		}
+		_, _ = rw.Write([]byte("hi"))
+	}))
+	defer srv.Close()
+	cl := NewClient(BotToken("hi"))
+
+	start := time.Now()
+	data, err := cl.Request(context.Background(), "GET", srv.URL+"/users/@me", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hi", string(data))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+	assert.True(t, time.Since(start) >= 100*time.Millisecond, "didn't wait: %s", time.Since(start))
+
+	t.Run("No Retries", func(t *testing.T) {
+		_, err := cl.Request(context.Background(), "GET", srv.URL+"/users/@me", nil, WithRateLimitRetries(0))
+		require.IsType(t, &RateLimitError{}, err)
+		rlErr := err.(*RateLimitError)
+		assert.Equal(t, "You are being rate limited.", rlErr.APIError.Message)
+		assert.True(t, errors.Is(err, ErrRateLimited))
+		assert.Equal(t, 100*time.Millisecond, rlErr.RetryAfter)
+		assert.Equal(t, "user", rlErr.Scope)
+		assert.False(t, rlErr.Global)
+	})
+}
+
+func TestClientRateLimitNoRetryAfter(t *testing.T) {
+	t.Run("Body", func(t *testing.T) {
+		var hits int32
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if atomic.AddInt32(&hits, 1) == 1 {
+				rw.WriteHeader(http.StatusTooManyRequests)
+				_, _ = rw.Write([]byte(`{"message":"You are being rate limited.","retry_after":0.1,"global":false}`))
+				return
+			}
+			_, _ = rw.Write([]byte("hi"))
+		}))
+		defer srv.Close()
+		cl := NewClient(BotToken("hi"))
+
+		start := time.Now()
+		data, err := cl.Request(context.Background(), "GET", srv.URL+"/users/@me", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "hi", string(data))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+		assert.True(t, time.Since(start) >= 100*time.Millisecond, "didn't wait: %s", time.Since(start))
+	})
+
+	t.Run("Fallback", func(t *testing.T) {
+		var hits int32
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if atomic.AddInt32(&hits, 1) == 1 {
+				rw.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			_, _ = rw.Write([]byte("hi"))
+		}))
+		defer srv.Close()
+		cl := NewClient(BotToken("hi"))
+
+		start := time.Now()
+		data, err := cl.Request(context.Background(), "GET", srv.URL+"/users/@me", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "hi", string(data))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+		assert.True(t, time.Since(start) >= fallbackRetryAfter, "didn't wait: %s", time.Since(start))
+	})
+}
+
+func TestClientRateLimitGlobal(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			rw.Header().Set("Retry-After", "0.2")
+			rw.Header().Set("X-RateLimit-Global", "true")
+			rw.Header().Set("X-RateLimit-Scope", "global")
+			rw.WriteHeader(http.StatusTooManyRequests)
+			_, _ = rw.Write([]byte(`{"message":"You are being rate limited.","retry_after":0.2,"global":true}`))
+			return
+		}
+		_, _ = rw.Write([]byte("hi"))
+	}))
+	defer srv.Close()
+	cl := NewClient(BotToken("hi"))
+
+	_, err := cl.Request(context.Background(), "GET", srv.URL+"/users/@me", nil, WithRateLimitRetries(0))
+	require.IsType(t, &RateLimitError{}, err)
+	assert.True(t, err.(*RateLimitError).Global)
+
+	// A global limit pauses requests to every route.
+	start := time.Now()
+	_, err = cl.Request(context.Background(), "GET", srv.URL+"/channels/1234", nil)
+	require.NoError(t, err)
+	assert.True(t, time.Since(start) >= 150*time.Millisecond, "didn't wait: %s", time.Since(start))
+}
@@ -0,0 +1,70 @@
+package dgo2poc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Returns an emoji in the form the API expects: unicode emoji as-is, custom emoji as "name:id".
+// Custom emoji may also be given in message format, eg. "<:name:id>" or "<a:name:id>".
+func EmojiAPIName(emoji string) string {
+	if strings.HasPrefix(emoji, "<") && strings.HasSuffix(emoji, ">") {
+		emoji = strings.TrimPrefix(strings.TrimSuffix(emoji[1:], ">"), "a:")
+		emoji = strings.TrimPrefix(emoji, ":")
+	}
+	return emoji
+}
+
+func (c *client) MessageReactionAdd(ctx context.Context, cid, mid, emoji string) error {
+	_, err := c.Request(ctx, "PUT", c.BaseURL+EndpointMessageReaction(cid, mid, emoji, "@me"), nil)
+	return err
+}
+
+func (c *client) MessageReactionRemove(ctx context.Context, cid, mid, emoji, uid string) error {
+	_, err := c.Request(ctx, "DELETE", c.BaseURL+EndpointMessageReaction(cid, mid, emoji, uid), nil)
+	return err
+}
+
+func (c *client) MessageReactionsRemoveAll(ctx context.Context, cid, mid string) error {
+	_, err := c.Request(ctx, "DELETE", c.BaseURL+EndpointMessageReactionsAll(cid, mid), nil)
+	return err
+}
+
+func (c *client) MessageReactionsRemoveEmoji(ctx context.Context, cid, mid, emoji string) error {
+	_, err := c.Request(ctx, "DELETE", c.BaseURL+EndpointMessageReactions(cid, mid, emoji), nil)
+	return err
+}
+
+func (c *client) MessageReactions(ctx context.Context, cid, mid, emoji string) *ReactionIterator {
+	urlStr := c.BaseURL + EndpointMessageReactions(cid, mid, emoji)
+	return &ReactionIterator{newIterator(ctx, 100, pageSnowflakes(c, urlStr, nil, "after", "", userID))}
+}
+
+// Iterator over users who reacted to a message; see Iterator.
+type ReactionIterator struct {
+	*Iterator[*discordgo.User]
+}
+
+// Stop after n users. Returns the iterator, for chaining.
+func (it *ReactionIterator) Limit(n int) *ReactionIterator {
+	it.Iterator.Limit(n)
+	return it
+}
+
+// Stop at the first user fn returns false for. Returns the iterator, for chaining.
+func (it *ReactionIterator) While(fn func(u *discordgo.User) bool) *ReactionIterator {
+	it.Iterator.While(fn)
+	return it
+}
+
+// Returns the current user; same as Value().
+func (it *ReactionIterator) User() *discordgo.User {
+	return it.Value()
+}
+
+// Returns a user's ID.
+func userID(u *discordgo.User) string {
+	return u.ID
+}
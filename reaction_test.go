@@ -0,0 +1,85 @@
+package dgo2poc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmojiAPIName(t *testing.T) {
+	assert.Equal(t, "👍", EmojiAPIName("👍"))
+	assert.Equal(t, "blobcat:1234", EmojiAPIName("blobcat:1234"))
+	assert.Equal(t, "blobcat:1234", EmojiAPIName("<:blobcat:1234>"))
+	assert.Equal(t, "blobcat:1234", EmojiAPIName("<a:blobcat:1234>"))
+}
+
+func TestClientMessageReactions(t *testing.T) {
+	srv, cl, rec := newTestServer(t, "")
+	defer srv.Close()
+	ctx := context.Background()
+
+	require.NoError(t, cl.MessageReactionAdd(ctx, "1234", "5678", "👍"))
+	assert.Equal(t, "PUT", rec.Last().Method)
+	assert.Equal(t, "/channels/1234/messages/5678/reactions/👍/@me", rec.Last().Path)
+
+	require.NoError(t, cl.MessageReactionAdd(ctx, "1234", "5678", "<:blobcat:1111>"))
+	assert.Equal(t, "/channels/1234/messages/5678/reactions/blobcat:1111/@me", rec.Last().Path)
+
+	require.NoError(t, cl.MessageReactionRemove(ctx, "1234", "5678", "👍", "@me"))
+	assert.Equal(t, "DELETE", rec.Last().Method)
+	assert.Equal(t, "/channels/1234/messages/5678/reactions/👍/@me", rec.Last().Path)
+
+	require.NoError(t, cl.MessageReactionRemove(ctx, "1234", "5678", "👍", "9999"))
+	assert.Equal(t, "DELETE", rec.Last().Method)
+	assert.Equal(t, "/channels/1234/messages/5678/reactions/👍/9999", rec.Last().Path)
+
+	require.NoError(t, cl.MessageReactionsRemoveAll(ctx, "1234", "5678"))
+	assert.Equal(t, "DELETE", rec.Last().Method)
+	assert.Equal(t, "/channels/1234/messages/5678/reactions", rec.Last().Path)
+
+	require.NoError(t, cl.MessageReactionsRemoveEmoji(ctx, "1234", "5678", "👍"))
+	assert.Equal(t, "DELETE", rec.Last().Method)
+	assert.Equal(t, "/channels/1234/messages/5678/reactions/👍", rec.Last().Path)
+}
+
+func TestClientMessageReactionsList(t *testing.T) {
+	// 250 users, with IDs 1-250.
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/channels/1234/messages/5678/reactions/👍", req.URL.Path)
+		assert.Equal(t, "100", req.URL.Query().Get("limit"))
+		after, _ := strconv.Atoi(req.URL.Query().Get("after"))
+		var users []*discordgo.User
+		for id := after + 1; id <= 250 && len(users) < 100; id++ {
+			users = append(users, &discordgo.User{ID: strconv.Itoa(id)})
+		}
+		assert.NoError(t, json.NewEncoder(rw).Encode(users))
+	}))
+	defer srv.Close()
+
+	it := newTestClient(srv).MessageReactions(context.Background(), "1234", "5678", "👍")
+	var n int
+	for it.Next() {
+		n++
+		assert.Equal(t, strconv.Itoa(n), it.User().ID)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, 250, n)
+
+	t.Run("Limit", func(t *testing.T) {
+		it := newTestClient(srv).MessageReactions(context.Background(), "1234", "5678", "👍").Limit(100)
+		var n int
+		for it.Next() {
+			n++
+			assert.Equal(t, strconv.Itoa(n), it.User().ID)
+		}
+		assert.NoError(t, it.Err())
+		assert.Equal(t, 100, n)
+	})
+}
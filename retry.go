@@ -0,0 +1,71 @@
+package dgo2poc
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// Policy for retrying requests that failed due to network errors or 5xx responses.
+// Rate limited requests are handled separately; see WithRateLimitRetries().
+type RetryPolicy struct {
+	// Max number of attempts, including the first one. 0 or 1 disables retries.
+	MaxAttempts int
+
+	// Backoff before the first retry; doubled for each subsequent retry, up to MaxBackoff.
+	// A MaxBackoff of 0 means the backoff is only capped at an hour.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// Default policy for all requests; override with WithRetryPolicy().
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  250 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+}
+
+// Ceiling for the backoff when RetryPolicy.MaxBackoff is 0.
+const uncappedMaxBackoff = time.Hour
+
+// Returns how long to wait before the nth retry (starting at 1), with jitter.
+func (p RetryPolicy) Backoff(n int) time.Duration {
+	max := p.MaxBackoff
+	if max <= 0 {
+		max = uncappedMaxBackoff
+	}
+	d := p.MinBackoff
+	if d <= 0 {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	for i := 1; i < n && d < max; i++ {
+		if d > max/2 {
+			d = max
+			break
+		}
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	// Wait at least half the backoff, so a thundering herd of retries is spread out, but not by too much.
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// Returns whether an HTTP method is safe to repeat. Discord's PATCH endpoints replace fields rather
+// than applying deltas, so they're included; POSTs generally aren't, but see WithIdempotent().
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "PATCH", "DELETE":
+		return true
+	}
+	return false
+}
+
+// Returns whether a response status indicates a transient failure, worth retrying.
+func isTransientStatus(status int) bool {
+	return status >= 500 && status != http.StatusNotImplemented
+}
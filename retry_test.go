@@ -0,0 +1,143 @@
+package dgo2poc
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRetryPolicy = RetryPolicy{MaxAttempts: 3, MinBackoff: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond}
+
+// Returns a server that responds with a 503 the first n times.
+func newFlakyServer(t *testing.T, n int32, hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(hits, 1) <= n {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = rw.Write([]byte(`upstream connect error`))
+			return
+		}
+		_, _ = rw.Write([]byte(`{"id":"1234"}`))
+	}))
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 10, MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for n, max := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		5: time.Second,
+		9: time.Second,
+	} {
+		d := p.Backoff(n)
+		assert.True(t, d >= max/2 && d <= max, "%d: %s not in [%s, %s]", n, d, max/2, max)
+	}
+	assert.Equal(t, time.Duration(0), RetryPolicy{}.Backoff(1))
+
+	t.Run("Uncapped", func(t *testing.T) {
+		p := RetryPolicy{MinBackoff: time.Second}
+		for n, max := range map[int]time.Duration{
+			1:  time.Second,
+			2:  2 * time.Second,
+			10: 512 * time.Second,
+			40: time.Hour,
+		} {
+			d := p.Backoff(n)
+			assert.True(t, d >= max/2 && d <= max, "%d: %s not in [%s, %s]", n, d, max/2, max)
+		}
+	})
+}
+
+func TestClientRetry(t *testing.T) {
+	t.Run("GET", func(t *testing.T) {
+		var hits int32
+		srv := newFlakyServer(t, 2, &hits)
+		defer srv.Close()
+		cl := NewClient(BotToken("hi"), WithRetryPolicy(testRetryPolicy))
+		data, err := cl.Request(context.Background(), "GET", srv.URL, nil)
+		require.NoError(t, err)
+		assert.Equal(t, `{"id":"1234"}`, string(data))
+		assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		var hits int32
+		srv := newFlakyServer(t, 3, &hits)
+		defer srv.Close()
+		cl := NewClient(BotToken("hi"), WithRetryPolicy(testRetryPolicy))
+		_, err := cl.Request(context.Background(), "GET", srv.URL, nil)
+		assert.EqualError(t, err, "503: upstream connect error")
+		assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("POST", func(t *testing.T) {
+		var hits int32
+		srv := newFlakyServer(t, 1, &hits)
+		defer srv.Close()
+		cl := NewClient(BotToken("hi"), WithRetryPolicy(testRetryPolicy))
+		_, err := cl.Request(context.Background(), "POST", srv.URL, nil)
+		assert.EqualError(t, err, "503: upstream connect error")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+
+		t.Run("Idempotent", func(t *testing.T) {
+			_, err := cl.Request(context.Background(), "POST", srv.URL, nil, WithIdempotent())
+			assert.NoError(t, err)
+		})
+	})
+
+	t.Run("Nonce", func(t *testing.T) {
+		var hits int32
+		var nonces []string
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			data, err := ioutil.ReadAll(req.Body)
+			assert.NoError(t, err)
+			var send MessageSend
+			assert.NoError(t, json.Unmarshal(data, &send))
+			assert.True(t, send.EnforceNonce)
+			nonces = append(nonces, send.Nonce)
+
+			if atomic.AddInt32(&hits, 1) == 1 {
+				rw.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_, _ = rw.Write([]byte(`{"id":"1234"}`))
+		}))
+		defer srv.Close()
+		msg, err := newTestClient(srv, WithRetryPolicy(testRetryPolicy)).ChannelMessageCreate(context.Background(), "1234", "hi")
+		require.NoError(t, err)
+		assert.Equal(t, "1234", msg.ID)
+		require.Len(t, nonces, 2)
+		assert.NotEmpty(t, nonces[0])
+		assert.Equal(t, nonces[0], nonces[1])
+	})
+
+	t.Run("Context Options", func(t *testing.T) {
+		var hits int32
+		srv := newFlakyServer(t, 1, &hits)
+		defer srv.Close()
+		cl := NewClient(BotToken("hi"), WithRetryPolicy(testRetryPolicy))
+		ctx := WithReqOptions(context.Background(), WithRetryPolicy(RetryPolicy{}))
+		_, err := cl.Request(ctx, "GET", srv.URL, nil)
+		assert.EqualError(t, err, "503: upstream connect error")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		var hits int32
+		srv := newFlakyServer(t, 1, &hits)
+		defer srv.Close()
+		cl := NewClient(BotToken("hi"), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Minute, MaxBackoff: time.Minute}))
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := cl.Request(ctx, "GET", srv.URL, nil)
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+}
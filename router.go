@@ -0,0 +1,479 @@
+package dgo2poc
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pkg/errors"
+)
+
+// Returned by Router.Dispatch() for commands, components and modals that don't have a handler.
+var (
+	ErrUnknownCommand   = errors.New("unknown command")
+	ErrUnknownComponent = errors.New("unknown component")
+	ErrUnknownModal     = errors.New("unknown modal")
+)
+
+// Routes interactions to handlers, eg. slash commands by their command/subcommand path.
+// Use Router.WSHandler() or Router.HandleInteraction() to receive interactions over the gateway or HTTP.
+//
+//	type EchoOptions struct {
+//		Text string `option:"text,required" description:"What to say"`
+//	}
+//
+//	r := NewRouter()
+//	HandleCommand(r, "echo", "Echoes text", func(ctx context.Context, i *discordgo.Interaction, opts *EchoOptions) error {
+//		return GetClient(ctx).InteractionRespond(ctx, i, opts.Text)
+//	})
+//	ws.AddHandler(r.WSHandler())
+type Router struct {
+	mu         sync.RWMutex
+	commands   []*routeNode
+	components []componentRoute
+	modals     map[string]modalHandler
+}
+
+// A component handler, matched by a custom ID prefix or pattern.
+type componentRoute struct {
+	Prefix  string
+	Pattern *regexp.Regexp
+	Handler ComponentHandler
+}
+
+// Handles a message component interaction; see Router.HandleComponent().
+type ComponentHandler func(ctx context.Context, c *ComponentInteraction) error
+
+// A message component interaction, eg. a button press or a select menu choice.
+type ComponentInteraction struct {
+	*discordgo.Interaction
+
+	// The component's data; shadows Interaction.Data.
+	Data discordgo.MessageComponentInteractionData
+
+	// For prefix handlers, the rest of the custom ID after the prefix; for pattern handlers,
+	// the pattern's submatches.
+	Args []string
+}
+
+// Returns the selected values of a select menu: option values, or IDs for other menu types.
+func (c *ComponentInteraction) Values() []string {
+	return c.Data.Values
+}
+
+// Returns the selected users of a user or mentionable select menu.
+func (c *ComponentInteraction) Users() []*discordgo.User {
+	var users []*discordgo.User
+	for _, id := range c.Data.Values {
+		if u := c.Data.Resolved.Users[id]; u != nil {
+			users = append(users, u)
+		}
+	}
+	return users
+}
+
+// Returns the selected roles of a role or mentionable select menu.
+func (c *ComponentInteraction) Roles() []*discordgo.Role {
+	var roles []*discordgo.Role
+	for _, id := range c.Data.Values {
+		if r := c.Data.Resolved.Roles[id]; r != nil {
+			roles = append(roles, r)
+		}
+	}
+	return roles
+}
+
+// Returns the selected channels of a channel select menu.
+func (c *ComponentInteraction) Channels() []*discordgo.Channel {
+	var channels []*discordgo.Channel
+	for _, id := range c.Data.Values {
+		if ch := c.Data.Resolved.Channels[id]; ch != nil {
+			channels = append(channels, ch)
+		}
+	}
+	return channels
+}
+
+// Responds by editing the message the component is attached to, in place.
+// Shorthand for GetClient(ctx).InteractionUpdate().
+func (c *ComponentInteraction) Update(ctx context.Context, opts ...EditOpt) error {
+	return GetClient(ctx).InteractionUpdate(ctx, c.Interaction, opts...)
+}
+
+// A command, subcommand group or subcommand.
+type routeNode struct {
+	Name        string
+	Description string
+	Children    []*routeNode
+
+	// Set for commands and subcommands with handlers.
+	Spec    *commandOptionsSpec
+	Handler commandHandler
+
+	// Autocomplete handlers, by option name.
+	Autocomplete map[string]autocompleteHandler
+}
+
+// Handles a command, given its options.
+type commandHandler func(ctx context.Context, i *discordgo.Interaction, data *discordgo.ApplicationCommandInteractionData, opts []*discordgo.ApplicationCommandInteractionDataOption) error
+
+// Returns choices for a command's focused option.
+type autocompleteHandler func(ctx context.Context, i *discordgo.Interaction, data *discordgo.ApplicationCommandInteractionData, opts []*discordgo.ApplicationCommandInteractionDataOption, focused *discordgo.ApplicationCommandInteractionDataOption) ([]*discordgo.ApplicationCommandOptionChoice, error)
+
+// Returns the child with the given name, or nil.
+func (n *routeNode) child(name string) *routeNode {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Creates a new, empty Router.
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Registers a handler for a slash command, by its path, eg. "ping", "config set" (a subcommand) or
+// "config channels set" (a subcommand in a group). Options are bound into T, a struct with option
+// tags, which also describe the options that are registered; see Router.Commands():
+//
+//	type Options struct {
+//		Name  string          `option:"name,required" description:"Your name" min:"1" max:"32"`
+//		Count *int            `option:"count" description:"How many" min:"1" max:"10"`
+//		Color string          `option:"color" description:"A colour" choices:"Red=red,Blue=blue"`
+//		User  *discordgo.User `option:"user" description:"Someone"`
+//	}
+//
+// Options may be strings, ints, floats or bools, pointers to those if they're optional, or
+// *discordgo.User, *discordgo.Member, *discordgo.Channel, *discordgo.Role or *discordgo.MessageAttachment.
+// Panics if T's tags are invalid, or the path conflicts with another handler.
+func HandleCommand[T any](r *Router, path, description string, fn func(ctx context.Context, i *discordgo.Interaction, opts *T) error) {
+	spec, err := parseCommandOptions(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		panic(errors.Wrap(err, path))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := r.node(path)
+	if n.Handler != nil || len(n.Children) > 0 {
+		panic(errors.Errorf("%s: already registered", path))
+	}
+	n.Description = description
+	n.Spec = spec
+	n.Handler = func(ctx context.Context, i *discordgo.Interaction, data *discordgo.ApplicationCommandInteractionData, opts []*discordgo.ApplicationCommandInteractionDataOption) error {
+		var v T
+		if err := spec.Bind(&v, opts, data.Resolved); err != nil {
+			return errors.Wrap(err, path)
+		}
+		return fn(ctx, i, &v)
+	}
+}
+
+// Registers an autocomplete handler for a command's option, which is called as the user types into it.
+// The focused option holds the partial value, usually as a string; other options the user has filled
+// in so far are bound into T as well as possible. Options with handlers are registered with
+// autocomplete enabled; see Router.Commands().
+//
+// Up to AutocompleteMaxChoices choices are sent, the rest are dropped. Handlers must return before
+// ctx's deadline, which is InteractionResponseTimeout after the interaction was created.
+// Panics if T's tags are invalid, or the option already has a handler.
+func HandleAutocomplete[T any](r *Router, path, option string, fn func(ctx context.Context, i *discordgo.Interaction, focused *discordgo.ApplicationCommandInteractionDataOption, opts *T) ([]*discordgo.ApplicationCommandOptionChoice, error)) {
+	spec, err := parseCommandOptions(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		panic(errors.Wrap(err, path))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := r.node(path)
+	if n.Autocomplete[option] != nil {
+		panic(errors.Errorf("%s: %s: already registered", path, option))
+	}
+	if n.Autocomplete == nil {
+		n.Autocomplete = make(map[string]autocompleteHandler)
+	}
+	n.Autocomplete[option] = func(ctx context.Context, i *discordgo.Interaction, data *discordgo.ApplicationCommandInteractionData, opts []*discordgo.ApplicationCommandInteractionDataOption, focused *discordgo.ApplicationCommandInteractionDataOption) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+		var v T
+		spec.BindPartial(&v, opts, data.Resolved)
+		return fn(ctx, i, focused, &v)
+	}
+}
+
+// Registers a handler for components whose custom IDs start with the given prefix, eg. "vote:" for
+// "vote:yes" and "vote:no"; the rest of the ID is passed in ComponentInteraction.Args. If several
+// prefixes match, the longest one wins. Panics if the prefix already has a handler.
+//
+//	r.HandleComponent("vote:", func(ctx context.Context, c *ComponentInteraction) error {
+//		return c.Update(ctx, EditWithContent("You voted "+c.Args[0]), EditWithoutComponents())
+//	})
+func (r *Router) HandleComponent(prefix string, fn ComponentHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, route := range r.components {
+		if route.Pattern == nil && route.Prefix == prefix {
+			panic(errors.Errorf("component prefix %q: already registered", prefix))
+		}
+	}
+	r.components = append(r.components, componentRoute{Prefix: prefix, Handler: fn})
+}
+
+// Registers a handler for components whose custom IDs match a regular expression, which must match
+// the whole ID; submatches are passed in ComponentInteraction.Args. Patterns are only tried if no
+// prefix matches, in the order they're registered. Panics if the pattern is invalid.
+//
+//	r.HandleComponentPattern(`page:(\d+)`, func(ctx context.Context, c *ComponentInteraction) error { ... })
+func (r *Router) HandleComponentPattern(pattern string, fn ComponentHandler) {
+	re := regexp.MustCompile(`^(?:` + pattern + `)$`)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.components = append(r.components, componentRoute{Pattern: re, Handler: fn})
+}
+
+// Sets the description of a command or subcommand group that has subcommands, eg. "config".
+func (r *Router) Describe(path, description string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.node(path).Description = description
+}
+
+// Returns the node for a path, creating it (and its parents) if needed. Must be called with mu held.
+func (r *Router) node(path string) *routeNode {
+	names := strings.Fields(path)
+	if len(names) == 0 || len(names) > 3 {
+		panic(errors.Errorf("%s: paths must have 1-3 parts", path))
+	}
+	root := &routeNode{Children: r.commands}
+	defer func() { r.commands = root.Children }()
+	parent := root
+	for _, name := range names {
+		if parent.Handler != nil {
+			panic(errors.Errorf("%s: %s already has a handler", path, parent.Name))
+		}
+		n := parent.child(name)
+		if n == nil {
+			n = &routeNode{Name: name, Description: name}
+			parent.Children = append(parent.Children, n)
+		}
+		parent = n
+	}
+	return parent
+}
+
+// Returns the commands to register for all handlers, eg. with Client.SyncCommands().
+func (r *Router) Commands() []*discordgo.ApplicationCommand {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cmds := make([]*discordgo.ApplicationCommand, len(r.commands))
+	for i, n := range r.commands {
+		cmds[i] = &discordgo.ApplicationCommand{
+			Type:        discordgo.ChatApplicationCommand,
+			Name:        n.Name,
+			Description: n.Description,
+			Options:     n.options(0),
+		}
+	}
+	return cmds
+}
+
+// Returns the options to register for a node at the given depth.
+func (n *routeNode) options(depth int) []*discordgo.ApplicationCommandOption {
+	if n.Spec != nil {
+		opts := n.Spec.Options()
+		for i, opt := range opts {
+			if n.Autocomplete[opt.Name] != nil && !opt.Autocomplete {
+				o := *opt
+				o.Autocomplete = true
+				opts[i] = &o
+			}
+		}
+		return opts
+	}
+	opts := make([]*discordgo.ApplicationCommandOption, len(n.Children))
+	for i, c := range n.Children {
+		typ := discordgo.ApplicationCommandOptionSubCommand
+		if len(c.Children) > 0 && depth == 0 {
+			typ = discordgo.ApplicationCommandOptionSubCommandGroup
+		}
+		opts[i] = &discordgo.ApplicationCommandOption{
+			Type:        typ,
+			Name:        c.Name,
+			Description: c.Description,
+			Options:     c.options(depth + 1),
+		}
+	}
+	return opts
+}
+
+// Dispatches an interaction to its handler, responding through GetClient(ctx) if needed.
+// Interactions of types without handlers are ignored.
+func (r *Router) Dispatch(ctx context.Context, i *discordgo.Interaction) error {
+	res, err := r.dispatch(ctx, i)
+	if err != nil || res == nil {
+		return err
+	}
+	return GetClient(ctx).InteractionAutocomplete(ctx, i, res.Data.Choices...)
+}
+
+// Dispatches an interaction to its handler. Returns a response for handlers that don't send one
+// themselves, eg. autocomplete, so it can be sent in the body of an HTTP interaction.
+func (r *Router) dispatch(ctx context.Context, i *discordgo.Interaction) (*discordgo.InteractionResponse, error) {
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		data := i.ApplicationCommandData()
+		n, opts, path := r.lookup(data.Name, data.Options)
+		if n == nil || n.Handler == nil {
+			return nil, errors.Wrap(ErrUnknownCommand, path)
+		}
+		return nil, n.Handler(ctx, i, &data, opts)
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		return r.autocomplete(ctx, i)
+	case discordgo.InteractionMessageComponent:
+		c := &ComponentInteraction{Interaction: i, Data: i.MessageComponentData()}
+		fn := r.component(c)
+		if fn == nil {
+			return nil, errors.Wrap(ErrUnknownComponent, c.Data.CustomID)
+		}
+		return nil, fn(ctx, c)
+	case discordgo.InteractionModalSubmit:
+		m := &ModalInteraction{Interaction: i, Data: i.ModalSubmitData()}
+		fn := r.modal(m)
+		if fn == nil {
+			return nil, errors.Wrap(ErrUnknownModal, m.Data.CustomID)
+		}
+		return nil, fn(ctx, m)
+	}
+	return nil, nil
+}
+
+// Looks up a modal's handler by its custom ID, and sets its OpenerID. Returns nil if there's none.
+func (r *Router) modal(m *ModalInteraction) modalHandler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id := m.Data.CustomID
+	if fn := r.modals[id]; fn != nil {
+		return fn
+	}
+	if idx := strings.LastIndexByte(id, ':'); idx >= 0 {
+		if fn := r.modals[id[:idx]]; fn != nil {
+			m.OpenerID = id[idx+1:]
+			return fn
+		}
+	}
+	return nil
+}
+
+// Looks up a component's handler by its custom ID, and sets its Args. Returns nil if there's none.
+func (r *Router) component(c *ComponentInteraction) ComponentHandler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id := c.Data.CustomID
+	var best *componentRoute
+	for i, route := range r.components {
+		if route.Pattern == nil && strings.HasPrefix(id, route.Prefix) && (best == nil || len(route.Prefix) > len(best.Prefix)) {
+			best = &r.components[i]
+		}
+	}
+	if best != nil {
+		c.Args = []string{strings.TrimPrefix(id, best.Prefix)}
+		return best.Handler
+	}
+	for _, route := range r.components {
+		if route.Pattern == nil {
+			continue
+		}
+		if m := route.Pattern.FindStringSubmatch(id); m != nil {
+			c.Args = m[1:]
+			return route.Handler
+		}
+	}
+	return nil
+}
+
+// Dispatches an autocomplete interaction to the focused option's handler.
+func (r *Router) autocomplete(ctx context.Context, i *discordgo.Interaction) (*discordgo.InteractionResponse, error) {
+	data := i.ApplicationCommandData()
+	n, opts, path := r.lookup(data.Name, data.Options)
+	var focused *discordgo.ApplicationCommandInteractionDataOption
+	for _, opt := range opts {
+		if opt.Focused {
+			focused = opt
+		}
+	}
+	if n == nil || focused == nil {
+		return nil, errors.Wrap(ErrUnknownCommand, path)
+	}
+	r.mu.RLock()
+	fn := n.Autocomplete[focused.Name]
+	r.mu.RUnlock()
+	if fn == nil {
+		return nil, errors.Wrapf(ErrUnknownCommand, "%s: %s: autocomplete", path, focused.Name)
+	}
+
+	created, err := SnowflakeTime(i.ID)
+	if err != nil {
+		created = time.Now()
+	}
+	ctx, cancel := context.WithDeadline(ctx, created.Add(InteractionResponseTimeout))
+	defer cancel()
+	choices, err := fn(ctx, i, &data, opts, focused)
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: %s: autocomplete", path, focused.Name)
+	}
+	if len(choices) > AutocompleteMaxChoices {
+		choices = choices[:AutocompleteMaxChoices]
+	}
+	if choices == nil {
+		choices = []*discordgo.ApplicationCommandOptionChoice{}
+	}
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	}, nil
+}
+
+// Looks up a command's handler, by its name and any subcommand (group) options.
+// Returns the node, the options for it, and the path to it.
+func (r *Router) lookup(name string, opts []*discordgo.ApplicationCommandInteractionDataOption) (*routeNode, []*discordgo.ApplicationCommandInteractionDataOption, string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	path := name
+	n := (&routeNode{Children: r.commands}).child(name)
+	for n != nil && len(opts) == 1 && (opts[0].Type == discordgo.ApplicationCommandOptionSubCommand ||
+		opts[0].Type == discordgo.ApplicationCommandOptionSubCommandGroup) {
+		path += " " + opts[0].Name
+		n, opts = n.child(opts[0].Name), opts[0].Options
+	}
+	return n, opts, path
+}
+
+// Returns a handler for interactions received over the gateway; pass it to WSClient.AddHandler().
+// Errors returned by handlers are logged.
+func (r *Router) WSHandler() wsHandler {
+	return OnInteractionCreate(func(ctx context.Context, ev *InteractionCreate) {
+		if err := r.Dispatch(ctx, &ev.Interaction); err != nil {
+			log.Printf("router: %v", err)
+		}
+	})
+}
+
+// Handles an interaction received over HTTP; pass it to NewInteractionHandler(). Handlers should
+// respond through the Client, eg. with Client.InteractionRespond(); autocomplete choices are returned
+// in the HTTP response instead. Errors returned by them are logged.
+func (r *Router) HandleInteraction(ctx context.Context, i *discordgo.Interaction) *discordgo.InteractionResponse {
+	res, err := r.dispatch(ctx, i)
+	if err != nil {
+		log.Printf("router: %v", err)
+	}
+	return res
+}
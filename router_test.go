@@ -0,0 +1,384 @@
+package dgo2poc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testGreetOptions struct {
+	Name    string                       `option:"name,required" description:"Who to greet" min:"1" max:"32"`
+	Times   *int                         `option:"times" description:"How many times" min:"1" max:"5"`
+	Style   string                       `option:"style" choices:"Loud=loud,Quiet=quiet"`
+	Ping    bool                         `option:"ping" description:"Whether to ping them"`
+	User    *discordgo.User              `option:"user" description:"Someone"`
+	Channel *discordgo.Channel           `option:"channel" description:"Where"`
+	Role    *discordgo.Role              `option:"role" description:"A role"`
+	File    *discordgo.MessageAttachment `option:"file" description:"A file"`
+	Ignored string
+}
+
+// Returns a command interaction, with data as JSON.
+func newTestCommandInteraction(t *testing.T, data string) *discordgo.Interaction {
+	return newTestInteractionWithData(t, discordgo.InteractionApplicationCommand, time.Now(), data)
+}
+
+// Returns an interaction of the given type and creation time, with data as JSON.
+func newTestInteractionWithData(t *testing.T, typ discordgo.InteractionType, created time.Time, data string) *discordgo.Interaction {
+	var i discordgo.Interaction
+	require.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(`{"id":"%s","application_id":"4321","type":%d,"token":"tok","data":%s}`, SnowflakeFromTime(created), typ, data)), &i))
+	return &i
+}
+
+func TestParseCommandOptions(t *testing.T) {
+	one, minLen := 1.0, 1
+	spec, err := parseCommandOptions(reflect.TypeOf(testGreetOptions{}))
+	require.NoError(t, err)
+	assert.Equal(t, []*discordgo.ApplicationCommandOption{
+		{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Who to greet", Required: true, MinLength: &minLen, MaxLength: 32},
+		{Type: discordgo.ApplicationCommandOptionInteger, Name: "times", Description: "How many times", MinValue: &one, MaxValue: 5},
+		{Type: discordgo.ApplicationCommandOptionString, Name: "style", Description: "style", Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{Name: "Loud", Value: "loud"},
+			{Name: "Quiet", Value: "quiet"},
+		}},
+		{Type: discordgo.ApplicationCommandOptionBoolean, Name: "ping", Description: "Whether to ping them"},
+		{Type: discordgo.ApplicationCommandOptionUser, Name: "user", Description: "Someone"},
+		{Type: discordgo.ApplicationCommandOptionChannel, Name: "channel", Description: "Where"},
+		{Type: discordgo.ApplicationCommandOptionRole, Name: "role", Description: "A role"},
+		{Type: discordgo.ApplicationCommandOptionAttachment, Name: "file", Description: "A file"},
+	}, spec.Options())
+
+	for _, v := range []interface{}{
+		0,
+		struct {
+			X []string `option:"x"`
+		}{},
+		struct {
+			X string `option:"x,requird"`
+		}{},
+		struct {
+			X bool `option:"x" min:"1"`
+		}{},
+		struct {
+			X int `option:"x" choices:"a"`
+		}{},
+	} {
+		_, err := parseCommandOptions(reflect.TypeOf(v))
+		assert.Error(t, err, "%T", v)
+	}
+
+	type misordered struct {
+		A string `option:"a,required"`
+		B string `option:"b"`
+		C string `option:"c,required"`
+	}
+	_, err = parseCommandOptions(reflect.TypeOf(misordered{}))
+	assert.EqualError(t, err, "misordered.C: required options must come before optional ones, like B")
+}
+
+func TestRouterCommands(t *testing.T) {
+	r := NewRouter()
+	noop := func(ctx context.Context, i *discordgo.Interaction, opts *struct{}) error { return nil }
+	HandleCommand(r, "greet", "Greets someone", func(ctx context.Context, i *discordgo.Interaction, opts *testGreetOptions) error { return nil })
+	HandleCommand(r, "config get", "Gets a setting", noop)
+	HandleCommand(r, "config channels set", "Sets a channel", noop)
+	r.Describe("config", "Configuration")
+	r.Describe("config channels", "Channel configuration")
+
+	cmds := r.Commands()
+	require.Len(t, cmds, 2)
+	assert.Equal(t, "greet", cmds[0].Name)
+	assert.Len(t, cmds[0].Options, 8)
+	assert.Equal(t, &discordgo.ApplicationCommand{
+		Type:        discordgo.ChatApplicationCommand,
+		Name:        "config",
+		Description: "Configuration",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "get", Description: "Gets a setting", Options: []*discordgo.ApplicationCommandOption{}},
+			{Type: discordgo.ApplicationCommandOptionSubCommandGroup, Name: "channels", Description: "Channel configuration", Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "set", Description: "Sets a channel", Options: []*discordgo.ApplicationCommandOption{}},
+			}},
+		},
+	}, cmds[1])
+
+	assert.Panics(t, func() { HandleCommand(r, "greet", "Again", noop) })
+	assert.Panics(t, func() { HandleCommand(r, "greet sub", "Under a handler", noop) })
+	assert.Panics(t, func() { HandleCommand(r, "a b c d", "Too deep", noop) })
+	assert.Panics(t, func() {
+		HandleCommand(r, "bad", "Bad tags", func(context.Context, *discordgo.Interaction, *struct {
+			X []int `option:"x"`
+		}) error {
+			return nil
+		})
+	})
+}
+
+func TestRouterDispatch(t *testing.T) {
+	r := NewRouter()
+	var got *testGreetOptions
+	HandleCommand(r, "greet", "Greets someone", func(ctx context.Context, i *discordgo.Interaction, opts *testGreetOptions) error {
+		got = opts
+		return nil
+	})
+	var gotSet *struct {
+		Channel *discordgo.Channel `option:"channel,required"`
+	}
+	HandleCommand(r, "config channels set", "Sets a channel", func(ctx context.Context, i *discordgo.Interaction, opts *struct {
+		Channel *discordgo.Channel `option:"channel,required"`
+	}) error {
+		gotSet = opts
+		return nil
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		i := newTestCommandInteraction(t, `{"id":"1","name":"greet","type":1,"options":[
+			{"name":"name","type":3,"value":"Meow"},
+			{"name":"times","type":4,"value":3},
+			{"name":"ping","type":5,"value":true},
+			{"name":"user","type":6,"value":"100"},
+			{"name":"role","type":8,"value":"200"},
+			{"name":"file","type":11,"value":"300"}
+		],"resolved":{
+			"users":{"100":{"id":"100","username":"cat"}},
+			"roles":{"200":{"id":"200","name":"cats"}},
+			"attachments":{"300":{"id":"300","filename":"cat.png"}}
+		}}`)
+		require.NoError(t, r.Dispatch(context.Background(), i))
+		require.NotNil(t, got)
+		assert.Equal(t, "Meow", got.Name)
+		require.NotNil(t, got.Times)
+		assert.Equal(t, 3, *got.Times)
+		assert.True(t, got.Ping)
+		assert.Equal(t, "cat", got.User.Username)
+		assert.Equal(t, "cats", got.Role.Name)
+		assert.Equal(t, "cat.png", got.File.Filename)
+		assert.Nil(t, got.Channel)
+	})
+
+	t.Run("Optional", func(t *testing.T) {
+		i := newTestCommandInteraction(t, `{"id":"1","name":"greet","type":1,"options":[{"name":"name","type":3,"value":"Meow"}]}`)
+		require.NoError(t, r.Dispatch(context.Background(), i))
+		assert.Equal(t, "Meow", got.Name)
+		assert.Nil(t, got.Times)
+	})
+
+	t.Run("Subcommand", func(t *testing.T) {
+		i := newTestCommandInteraction(t, `{"id":"1","name":"config","type":1,"options":[
+			{"name":"channels","type":2,"options":[{"name":"set","type":1,"options":[{"name":"channel","type":7,"value":"100"}]}]}
+		],"resolved":{"channels":{"100":{"id":"100","name":"general"}}}}`)
+		require.NoError(t, r.Dispatch(context.Background(), i))
+		require.NotNil(t, gotSet)
+		assert.Equal(t, "general", gotSet.Channel.Name)
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		i := newTestCommandInteraction(t, `{"id":"1","name":"config","type":1,"options":[
+			{"name":"channels","type":2,"options":[{"name":"get","type":1}]}
+		]}`)
+		err := r.Dispatch(context.Background(), i)
+		assert.True(t, errors.Is(err, ErrUnknownCommand), "%v", err)
+		assert.EqualError(t, err, "config channels get: unknown command")
+	})
+
+	t.Run("Mistyped", func(t *testing.T) {
+		i := newTestCommandInteraction(t, `{"id":"1","name":"greet","type":1,"options":[{"name":"name","type":3,"value":1}]}`)
+		assert.EqualError(t, r.Dispatch(context.Background(), i), "greet: name: can't bind float64 to string")
+	})
+
+	t.Run("Ignored", func(t *testing.T) {
+		i := &discordgo.Interaction{Type: discordgo.InteractionPing}
+		assert.NoError(t, r.Dispatch(context.Background(), i))
+	})
+}
+
+func TestRouterTransports(t *testing.T) {
+	srv, cl, rec := newTestServer(t, "")
+	defer srv.Close()
+
+	r := NewRouter()
+	HandleCommand(r, "echo", "Echoes text", func(ctx context.Context, i *discordgo.Interaction, opts *struct {
+		Text string `option:"text,required"`
+	}) error {
+		return GetClient(ctx).InteractionRespond(ctx, i, opts.Text)
+	})
+	data := `{"id":"1234","application_id":"4321","type":2,"token":"tok","data":{"id":"1","name":"echo","type":1,"options":[{"name":"text","type":3,"value":"%s"}]}}`
+
+	t.Run("Gateway", func(t *testing.T) {
+		var pre, main wsHandlers
+		pre.Add(r.WSHandler()) // Handlers in main run asynchronously.
+		ctx := withClient(context.Background(), cl)
+		require.NoError(t, dispatch(ctx, "INTERACTION_CREATE", []byte(fmt.Sprintf(data, "gateway")), &pre, &main))
+		assert.Equal(t, "/interactions/1234/tok/callback", rec.Last().Path)
+		assert.JSONEq(t, `{"type":4,"data":{"content":"gateway","tts":false,"embeds":null,"components":null}}`, rec.Last().Body)
+	})
+
+	t.Run("HTTP", func(t *testing.T) {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		h := NewInteractionHandler(cl, pub, r.HandleInteraction)
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, newSignedInteractionRequest(t, priv, fmt.Sprintf(data, "http")))
+		assert.Equal(t, http.StatusAccepted, rw.Code)
+		assert.Equal(t, "/interactions/1234/tok/callback", rec.Last().Path)
+		assert.JSONEq(t, `{"type":4,"data":{"content":"http","tts":false,"embeds":null,"components":null}}`, rec.Last().Body)
+	})
+}
+
+func TestRouterAutocomplete(t *testing.T) {
+	type Options struct {
+		Animal string `option:"animal,required"`
+		Count  int    `option:"count"`
+		Legs   int    `option:"legs"`
+	}
+	r := NewRouter()
+	HandleCommand(r, "pet", "Pets an animal", func(context.Context, *discordgo.Interaction, *Options) error { return nil })
+	var got *Options
+	var gotFocused *discordgo.ApplicationCommandInteractionDataOption
+	HandleAutocomplete(r, "pet", "animal", func(ctx context.Context, i *discordgo.Interaction, focused *discordgo.ApplicationCommandInteractionDataOption, opts *Options) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+		got, gotFocused = opts, focused
+		_, ok := ctx.Deadline()
+		assert.True(t, ok, "no deadline")
+		var choices []*discordgo.ApplicationCommandOptionChoice
+		for n := 0; n < 30; n++ {
+			name := fmt.Sprintf("%s%d", focused.StringValue(), n)
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: name, Value: name})
+		}
+		return choices, nil
+	})
+	assert.Panics(t, func() {
+		HandleAutocomplete(r, "pet", "animal", func(context.Context, *discordgo.Interaction, *discordgo.ApplicationCommandInteractionDataOption, *Options) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+			return nil, nil
+		})
+	})
+
+	cmds := r.Commands()
+	require.Len(t, cmds, 1)
+	assert.True(t, cmds[0].Options[0].Autocomplete)
+	assert.False(t, cmds[0].Options[1].Autocomplete)
+
+	data := `{"id":"1","name":"pet","type":1,"options":[
+		{"name":"animal","type":3,"value":"ca","focused":true},
+		{"name":"count","type":4,"value":2},
+		{"name":"legs","type":4,"value":"fou"}
+	]}`
+
+	t.Run("HTTP", func(t *testing.T) {
+		i := newTestInteractionWithData(t, discordgo.InteractionApplicationCommandAutocomplete, time.Now(), data)
+		res := r.HandleInteraction(context.Background(), i)
+		require.NotNil(t, res)
+		assert.Equal(t, discordgo.InteractionApplicationCommandAutocompleteResult, res.Type)
+		require.Len(t, res.Data.Choices, AutocompleteMaxChoices)
+		assert.Equal(t, "ca0", res.Data.Choices[0].Name)
+		assert.Equal(t, "ca", gotFocused.StringValue())
+		assert.Equal(t, &Options{Count: 2}, got)
+	})
+
+	t.Run("Gateway", func(t *testing.T) {
+		srv, cl, rec := newTestServer(t, "")
+		defer srv.Close()
+		i := newTestInteractionWithData(t, discordgo.InteractionApplicationCommandAutocomplete, time.Now(), data)
+		require.NoError(t, r.Dispatch(withClient(context.Background(), cl), i))
+		assert.Equal(t, "/interactions/"+i.ID+"/tok/callback", rec.Last().Path)
+		assert.Contains(t, rec.Last().Body, `{"type":8,"data":{"choices":[{"name":"ca0","value":"ca0"},`)
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		r := NewRouter()
+		HandleAutocomplete(r, "pet", "animal", func(ctx context.Context, i *discordgo.Interaction, focused *discordgo.ApplicationCommandInteractionDataOption, opts *Options) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+			<-ctx.Done()
+			return nil, nil
+		})
+		i := newTestInteractionWithData(t, discordgo.InteractionApplicationCommandAutocomplete, time.Now().Add(-InteractionResponseTimeout), data)
+		_, err := r.dispatch(context.Background(), i)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), "%v", err)
+		assert.EqualError(t, err, "pet: animal: autocomplete: context deadline exceeded")
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		i := newTestInteractionWithData(t, discordgo.InteractionApplicationCommandAutocomplete, time.Now(), `{"id":"1","name":"pet","type":1,"options":[
+			{"name":"count","type":4,"value":"2","focused":true}
+		]}`)
+		_, err := r.dispatch(context.Background(), i)
+		assert.True(t, errors.Is(err, ErrUnknownCommand), "%v", err)
+		assert.EqualError(t, err, "pet: count: autocomplete: unknown command")
+	})
+}
+
+func TestRouterComponents(t *testing.T) {
+	srv, cl, rec := newTestServer(t, "")
+	defer srv.Close()
+	ctx := withClient(context.Background(), cl)
+
+	r := NewRouter()
+	var got *ComponentInteraction
+	var gotBy string
+	handler := func(name string) ComponentHandler {
+		return func(ctx context.Context, c *ComponentInteraction) error {
+			got, gotBy = c, name
+			return nil
+		}
+	}
+	r.HandleComponent("vote:", func(ctx context.Context, c *ComponentInteraction) error {
+		return c.Update(ctx, EditWithContent("You voted "+c.Args[0]), EditWithoutComponents())
+	})
+	r.HandleComponent("vote:admin:", handler("admin"))
+	r.HandleComponentPattern(`page:(\d+):(\d+)`, handler("page"))
+	r.HandleComponentPattern(`pick`, handler("pick"))
+	assert.Panics(t, func() { r.HandleComponent("vote:", handler("again")) })
+	assert.Panics(t, func() { r.HandleComponentPattern(`(`, handler("bad")) })
+
+	component := func(data string) *discordgo.Interaction {
+		return newTestInteractionWithData(t, discordgo.InteractionMessageComponent, time.Now(), data)
+	}
+
+	t.Run("Update", func(t *testing.T) {
+		i := component(`{"custom_id":"vote:yes","component_type":2}`)
+		require.NoError(t, r.Dispatch(ctx, i))
+		assert.Equal(t, "/interactions/"+i.ID+"/tok/callback", rec.Last().Path)
+		assert.JSONEq(t, `{"type":7,"data":{"content":"You voted yes","components":[]}}`, rec.Last().Body)
+	})
+
+	t.Run("LongestPrefix", func(t *testing.T) {
+		require.NoError(t, r.Dispatch(ctx, component(`{"custom_id":"vote:admin:reset","component_type":2}`)))
+		assert.Equal(t, "admin", gotBy)
+		assert.Equal(t, []string{"reset"}, got.Args)
+	})
+
+	t.Run("Pattern", func(t *testing.T) {
+		require.NoError(t, r.Dispatch(ctx, component(`{"custom_id":"page:3:10","component_type":2}`)))
+		assert.Equal(t, "page", gotBy)
+		assert.Equal(t, []string{"3", "10"}, got.Args)
+	})
+
+	t.Run("Select", func(t *testing.T) {
+		require.NoError(t, r.Dispatch(ctx, component(`{"custom_id":"pick","component_type":7,"values":["100","200"],"resolved":{
+			"users":{"100":{"id":"100","username":"cat"}},
+			"roles":{"200":{"id":"200","name":"cats"}}
+		}}`)))
+		assert.Equal(t, "pick", gotBy)
+		assert.Equal(t, []string{"100", "200"}, got.Values())
+		require.Len(t, got.Users(), 1)
+		assert.Equal(t, "cat", got.Users()[0].Username)
+		require.Len(t, got.Roles(), 1)
+		assert.Equal(t, "cats", got.Roles()[0].Name)
+		assert.Empty(t, got.Channels())
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		err := r.Dispatch(ctx, component(`{"custom_id":"picky","component_type":2}`))
+		assert.True(t, errors.Is(err, ErrUnknownComponent), "%v", err)
+		assert.EqualError(t, err, "picky: unknown component")
+	})
+}
@@ -0,0 +1,28 @@
+package dgo2poc
+
+import (
+	"strconv"
+	"time"
+)
+
+// Discord's epoch, the first second of 2015, in milliseconds since the Unix epoch.
+const DiscordEpoch = 1420070400000
+
+// Returns the time a snowflake ID was created.
+func SnowflakeTime(id string) (time.Time, error) {
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	ms := int64(n>>22) + DiscordEpoch
+	return time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond)), nil
+}
+
+// Returns the lowest possible snowflake ID for a time, eg. for use with MessagesAfter().
+func SnowflakeFromTime(t time.Time) string {
+	ms := t.UnixNano()/int64(time.Millisecond) - DiscordEpoch
+	if ms < 0 {
+		ms = 0
+	}
+	return strconv.FormatUint(uint64(ms)<<22, 10)
+}
@@ -0,0 +1,28 @@
+package dgo2poc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnowflakeTime(t *testing.T) {
+	ts, err := SnowflakeTime("175928847299117063")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2016, 4, 30, 11, 18, 25, 796*int(time.Millisecond), time.UTC), ts.UTC())
+
+	_, err = SnowflakeTime("@me")
+	assert.Error(t, err)
+}
+
+func TestSnowflakeFromTime(t *testing.T) {
+	ts := time.Date(2016, 4, 30, 11, 18, 25, 796*int(time.Millisecond), time.UTC)
+	id := SnowflakeFromTime(ts)
+	assert.Equal(t, "175928847298985984", id)
+
+	ts2, err := SnowflakeTime(id)
+	require.NoError(t, err)
+	assert.Equal(t, ts, ts2.UTC())
+}
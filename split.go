@@ -0,0 +1,248 @@
+package dgo2poc
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The maximum length of a message's content, in characters.
+const MessageMaxLength = 2000
+
+// A code block fence.
+const codeFence = "```"
+
+// The smallest max SplitMessage() accepts: enough to reopen a code block, hold a character of it,
+// and close it again.
+const SplitMessageMinLength = len(codeFence+"\n") + 1 + len("\n"+codeFence)
+
+// Splits content into chunks of at most max characters, for Client.ChannelMessageCreateSplit().
+// Chunks are split on the last line break that fits, else the last space, else the last grapheme
+// boundary, so that combined emoji and accented letters aren't broken up. Code blocks, which are
+// opened and closed by fences at the start of a line, are closed at the end of one chunk and
+// reopened, with the same language, at the start of the next; if the language doesn't fit, it's
+// dropped. Backtick runs are never split, or moved to the start of a line where they'd become a
+// fence. A max below SplitMessageMinLength is raised to it.
+func SplitMessage(content string, max int) []string {
+	if max < SplitMessageMinLength {
+		max = SplitMessageMinLength
+	}
+
+	var chunks []string
+	var prefix string // Reopens a code block left open by the previous chunk.
+	for utf8.RuneCountInString(prefix)+utf8.RuneCountInString(content) > max {
+		budget := max - utf8.RuneCountInString(prefix)
+		end, next := splitPoint(prefix, content, budget)
+		lang, _, open := openCodeBlock(prefix + content[:end])
+		if open {
+			// Leave room to close the code block.
+			end, next = splitPoint(prefix, content, budget-len("\n"+codeFence))
+			var fence int
+			lang, fence, open = openCodeBlock(prefix + content[:end])
+			if open && fence == len(prefix) && opensCodeBlock(prefix+content[:end]) {
+				// The block's opening line leaves no room for anything else; drop its language.
+				n := backtickRun(content)
+				if lang := fenceLanguage(content[n:]); lang != "" {
+					content = content[:n] + content[n+len(lang):]
+					continue
+				}
+			}
+		}
+		if _, fence, _ := openCodeBlock(prefix + content[:end]); open && fence >= len(prefix) {
+			// The opening line may have been split; its language is on the whole line.
+			line := content[fence-len(prefix):]
+			lang = fenceLanguage(line[backtickRun(line):])
+		}
+		chunk := prefix + content[:end]
+		lineStart := next > 0 && content[next-1] == '\n'
+		content = content[next:]
+
+		prefix = ""
+		if open {
+			chunk = strings.TrimRight(chunk, "\n") + "\n" + codeFence
+			rest := strings.TrimLeft(content, "\n")
+			if n := backtickRun(rest); n >= len(codeFence) && (lineStart || len(rest) < len(content)) {
+				// The block ends right away; it's closed now, so don't reopen an empty one.
+				content = strings.TrimPrefix(rest[n:], "\n")
+			} else {
+				prefix = codeFence + lang + "\n"
+				if utf8.RuneCountInString(prefix)+len("\n"+codeFence) >= max {
+					// No room for the language.
+					prefix = codeFence + "\n"
+				}
+			}
+		}
+		chunks = append(chunks, chunk)
+	}
+	if content != "" || len(chunks) == 0 {
+		chunks = append(chunks, prefix+content)
+	}
+	return chunks
+}
+
+// Returns where to split s, which follows prefix, so the first part holds at most budget characters:
+// the end of the first part, and the start of the second, which skips the line break or space that
+// was split on.
+func splitPoint(prefix, s string, budget int) (end, next int) {
+	if budget < 1 {
+		budget = 1
+	}
+
+	// Find the byte offset of the first character that doesn't fit.
+	limit := 0
+	for n := 0; n < budget && limit < len(s); n++ {
+		_, size := utf8.DecodeRuneInString(s[limit:])
+		limit += size
+	}
+	if limit >= len(s) {
+		return len(s), len(s)
+	}
+
+	// The separator itself may sit just past the limit, since it's dropped. Don't split right after
+	// a code block is opened, which would leave an empty block behind, or right before triple
+	// backticks, which would turn them into a fence at the start of the next chunk.
+	window := s[:limit+1]
+	for i := strings.LastIndexByte(window, '\n'); i > 0; i = strings.LastIndexByte(window[:i], '\n') {
+		if !opensCodeBlock(prefix + s[:i]) {
+			return i, i + 1
+		}
+	}
+	for i := strings.LastIndexFunc(window, unicode.IsSpace); i > 0; i = strings.LastIndexFunc(window[:i], unicode.IsSpace) {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		if !opensCodeBlock(prefix+s[:i]) && backtickRun(s[i+size:]) < len(codeFence) {
+			return i, i + size
+		}
+	}
+	if i := graphemeBoundary(s, limit); i > 0 {
+		if j := backtickRunStart(s, i); j > 0 {
+			i = j
+		}
+		if backtickRun(s[i:]) >= len(codeFence) && s[i-1] != '\n' {
+			_, size := utf8.DecodeLastRuneInString(s[:i])
+			if j := graphemeBoundary(s, i-size); j > 0 {
+				i = j
+			}
+		}
+		// Don't leave an empty code block behind either; split before its fence instead.
+		if _, fence, _ := openCodeBlock(prefix + s[:i]); fence > len(prefix)+1 && opensCodeBlock(prefix+s[:i]) {
+			i = fence - len(prefix)
+			return i - 1, i // Drop the line break before the fence.
+		}
+		return i, i
+	}
+	return limit, limit
+}
+
+// If the byte offset i falls inside a run of backticks, returns the start of the run, else i.
+func backtickRunStart(s string, i int) int {
+	if i >= len(s) || s[i] != '`' {
+		return i
+	}
+	for i > 0 && s[i-1] == '`' {
+		i--
+	}
+	return i
+}
+
+// Returns the number of backticks at the start of s.
+func backtickRun(s string) int {
+	n := 0
+	for n < len(s) && s[n] == '`' {
+		n++
+	}
+	return n
+}
+
+// Reports whether s ends inside a code block that has nothing in it yet.
+func opensCodeBlock(s string) bool {
+	lang, fence, open := openCodeBlock(s)
+	if !open {
+		return false
+	}
+	s = s[fence:]
+	return strings.TrimSpace(s[backtickRun(s)+len(lang):]) == ""
+}
+
+// Returns the last grapheme cluster boundary at or before the byte offset i. This approximates
+// Unicode's rules for the common cases: combining marks, variation selectors, emoji modifiers and
+// tags belong to the preceding character, zero-width joiners join the characters around them, and
+// regional indicators (flags) come in pairs.
+func graphemeBoundary(s string, i int) int {
+	for i > 0 {
+		r, _ := utf8.DecodeRuneInString(s[i:])
+		prev, size := utf8.DecodeLastRuneInString(s[:i])
+		switch {
+		case isGraphemeExtend(r) || prev == '\u200d':
+		case isRegionalIndicator(r) && isRegionalIndicator(prev) && regionalIndicatorsBefore(s[:i])%2 == 1:
+		default:
+			return i
+		}
+		i -= size
+	}
+	return i
+}
+
+// Reports whether r extends the preceding character.
+func isGraphemeExtend(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		r == '\u200d' || // Zero-width joiner.
+		(r >= 0xFE00 && r <= 0xFE0F) || // Variation selectors.
+		(r >= 0x1F3FB && r <= 0x1F3FF) || // Emoji skin tone modifiers.
+		(r >= 0xE0020 && r <= 0xE007F) // Tags, used in subdivision flags.
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// Returns the number of consecutive regional indicators at the end of s.
+func regionalIndicatorsBefore(s string) int {
+	n := 0
+	for len(s) > 0 {
+		r, size := utf8.DecodeLastRuneInString(s)
+		if !isRegionalIndicator(r) {
+			break
+		}
+		n++
+		s = s[:len(s)-size]
+	}
+	return n
+}
+
+// Reports whether s ends inside a code block, and if so, the block's language and the offset of
+// its opening fence. Only fences at the start of a line open and close code blocks; triple
+// backticks elsewhere are left alone.
+func openCodeBlock(s string) (lang string, fence int, open bool) {
+	for i := 0; i < len(s); {
+		line := s[i:]
+		end := strings.IndexByte(line, '\n')
+		if end >= 0 {
+			line = line[:end]
+		}
+		if n := backtickRun(line); n >= len(codeFence) {
+			open = !open
+			if open {
+				lang, fence = fenceLanguage(line[n:]), i
+			}
+		}
+		if end < 0 {
+			break
+		}
+		i += end + 1
+	}
+	if !open {
+		return "", 0, false
+	}
+	return lang, fence, true
+}
+
+// Returns the language named by the rest of a code block's opening line, if it's a single word.
+func fenceLanguage(s string) string {
+	if j := strings.IndexByte(s, '\n'); j >= 0 {
+		s = s[:j]
+	}
+	if strings.ContainsAny(s, " `") {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,162 @@
+package dgo2poc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitMessage(t *testing.T) {
+	for name, tc := range map[string]struct {
+		Content string
+		Max     int
+		Chunks  []string
+	}{
+		"Short":    {"hello", 10, []string{"hello"}},
+		"Empty":    {"", 10, []string{""}},
+		"Exact":    {"0123456789", 10, []string{"0123456789"}},
+		"Lines":    {"one two\nthree\nfour five", 10, []string{"one two", "three", "four five"}},
+		"Words":    {"one two three four", 12, []string{"one two", "three four"}},
+		"Long":     {"abcdefghijklmnopqrstuvwxyz", 9, []string{"abcdefghi", "jklmnopqr", "stuvwxyz"}},
+		"Accents":  {"aaaaaaaae\u0301e\u0301", 9, []string{"aaaaaaaa", "e\u0301e\u0301"}},
+		"ZWJ":      {"aaaaaaa👩‍💻", 9, []string{"aaaaaaa", "👩‍💻"}},
+		"SkinTone": {"aaaaaaaa👍🏽", 9, []string{"aaaaaaaa", "👍🏽"}},
+		"Flags":    {"aaaaaa🇬🇧🇫🇷", 9, []string{"aaaaaa🇬🇧", "🇫🇷"}},
+		"TooSmall": {"abcdefghijkl", 5, []string{"abcdefghi", "jkl"}},
+		"Code": {"intro\n```go\nline 1\nline 2\nline 3\n```\noutro", 24, []string{
+			"intro\n```go\nline 1\n```",
+			"```go\nline 2\nline 3\n```",
+			"outro",
+		}},
+		"CodeNoLang": {"```\naaaa bbbb cccc\n```", 17, []string{
+			"```\naaaa bbbb\n```",
+			"```\ncccc\n```",
+		}},
+		"InlineCode":  {"use ```x``` here and there", 16, []string{"use ```x``` here", "and there"}},
+		"Fence":       {"abc ``` def", 5, []string{"abc ```", "def"}},
+		"InlineFence": {"x ```y\nline one\nline two", 12, []string{"x ```y", "line one", "line two"}},
+		"FenceWord":   {"abcdefghij```", 11, []string{"abcdefghi", "j```"}},
+		"CodeEnd": {"```\naaaa bbbb\n```\nok", 13, []string{
+			"```\naaaa\n```",
+			"```\nbbbb\n```",
+			"ok",
+		}},
+		"CodeLongFence": {"`````go\nword ```\n\n\nmore code", 16, []string{
+			"`````go\nword\n```",
+			"```go\n ```\n```",
+			"```go\nmore code",
+		}},
+		"CodeLongLang": {"```typescript\nconst a = 1;\n```", 16, []string{
+			"```\nconst a\n```",
+			"```\n= 1;\n```",
+		}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			chunks := SplitMessage(tc.Content, tc.Max)
+			assert.Equal(t, tc.Chunks, chunks)
+			max := tc.Max
+			if max < SplitMessageMinLength {
+				max = SplitMessageMinLength
+			}
+			for _, chunk := range chunks {
+				assert.LessOrEqual(t, utf8.RuneCountInString(chunk), max, chunk)
+				if strings.HasSuffix(chunk, codeFence) {
+					assert.False(t, opensCodeBlock(strings.TrimSuffix(chunk, codeFence)), "empty code block: %q", chunk)
+				}
+			}
+		})
+	}
+
+	t.Run("Logs", func(t *testing.T) {
+		var lines []string
+		for i := 0; i < 500; i++ {
+			lines = append(lines, "2023-01-02T03:04:05Z INFO something happened, as it does")
+		}
+		content := "```\n" + strings.Join(lines, "\n") + "\n```"
+		chunks := SplitMessage(content, MessageMaxLength)
+		require.Greater(t, len(chunks), 1)
+		for _, chunk := range chunks {
+			assert.LessOrEqual(t, utf8.RuneCountInString(chunk), MessageMaxLength)
+			assert.True(t, strings.HasPrefix(chunk, "```\n"), chunk)
+			assert.True(t, strings.HasSuffix(chunk, "\n```"), chunk)
+			_, _, open := openCodeBlock(chunk)
+			assert.False(t, open)
+		}
+	})
+}
+
+func TestClientChannelMessageCreateSplit(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"id":"5678"}`)
+	defer srv.Close()
+	ctx := context.Background()
+	content := strings.Repeat("a", 1500) + "\n" + strings.Repeat("b", 1500)
+	row := ActionRow(Button(discordgo.PrimaryButton, "A", "a"))
+
+	msgs, err := cl.ChannelMessageCreateSplit(ctx, "1234", content,
+		SendWithComponents(row),
+		SendWithFile("log.txt", strings.NewReader("log")),
+		SendWithFlags(discordgo.MessageFlagsSuppressEmbeds),
+		SendWithNonce("mine", true),
+		func(send *MessageSend) { send.Reference = &discordgo.MessageReference{MessageID: "1111"} },
+	)
+	require.NoError(t, err)
+	assert.Len(t, msgs, 2)
+
+	reqs := rec.All()
+	require.Len(t, reqs, 2)
+	first, last := reqs[0], reqs[1]
+	assert.Contains(t, first.Header.Get("Content-Type"), "application/json")
+	assert.Contains(t, first.Body, `"content":"`+strings.Repeat("a", 1500)+`"`)
+	assert.Contains(t, first.Body, `"message_reference":{"message_id":"1111"}`)
+	assert.Contains(t, first.Body, `"flags":4`)
+	assert.Contains(t, first.Body, `"components":null`)
+	assert.NotContains(t, first.Body, `"nonce":"mine"`)
+	assert.NotContains(t, first.Body, `attachments`)
+
+	assert.Contains(t, last.Header.Get("Content-Type"), "multipart/form-data")
+	assert.Contains(t, last.Body, strings.Repeat("b", 1500))
+	assert.Contains(t, last.Body, `"custom_id":"a"`)
+	assert.Contains(t, last.Body, `"nonce":"mine"`)
+	assert.Contains(t, last.Body, `"flags":4`)
+	assert.NotContains(t, last.Body, `message_reference`)
+
+	t.Run("Short", func(t *testing.T) {
+		msgs, err := cl.ChannelMessageCreateSplit(ctx, "1234", "hi")
+		require.NoError(t, err)
+		assert.Len(t, msgs, 1)
+	})
+
+	t.Run("TooLong", func(t *testing.T) {
+		_, err := cl.ChannelMessageCreate(ctx, "1234", content)
+		assert.EqualError(t, err, "content: 3001 exceeds the limit of 2000")
+		var lerr *ContentLengthError
+		require.True(t, errors.As(err, &lerr))
+		assert.Equal(t, 3001, lerr.Length)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		var n int32
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if atomic.AddInt32(&n, 1) > 1 {
+				rw.WriteHeader(http.StatusForbidden)
+				_, _ = rw.Write([]byte(`{"code":50013,"message":"Missing Permissions"}`))
+				return
+			}
+			_, _ = rw.Write([]byte(`{"id":"5678"}`))
+		}))
+		defer srv.Close()
+		msgs, err := newTestClient(srv).ChannelMessageCreateSplit(ctx, "1234", content)
+		assert.EqualError(t, err, "403: Missing Permissions")
+		require.Len(t, msgs, 1)
+		assert.Equal(t, "5678", msgs[0].ID)
+	})
+}
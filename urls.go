@@ -1,8 +1,98 @@
 package dgo2poc
 
+import (
+	"net/url"
+)
+
 func EndpointUser(uid string) string { return "/users/" + uid }
 
-func EndpointChannelMessages(cid string) string { return "/channels/" + cid + "/messages" }
+func EndpointChannel(cid string) string { return "/channels/" + cid }
+
+func EndpointChannelMessages(cid string) string { return EndpointChannel(cid) + "/messages" }
+
+func EndpointChannelMessage(cid, mid string) string { return EndpointChannelMessages(cid) + "/" + mid }
+
+func EndpointChannelMessagesBulkDelete(cid string) string {
+	return EndpointChannelMessages(cid) + "/bulk-delete"
+}
+
+func EndpointMessageReactionsAll(cid, mid string) string {
+	return EndpointChannelMessage(cid, mid) + "/reactions"
+}
+
+func EndpointMessageReactions(cid, mid, emoji string) string {
+	return EndpointMessageReactionsAll(cid, mid) + "/" + url.PathEscape(EmojiAPIName(emoji))
+}
+
+func EndpointMessageReaction(cid, mid, emoji, uid string) string {
+	return EndpointMessageReactions(cid, mid, emoji) + "/" + uid
+}
+
+func EndpointChannelPins(cid string) string { return EndpointChannel(cid) + "/pins" }
+
+func EndpointChannelPin(cid, mid string) string { return EndpointChannelPins(cid) + "/" + mid }
+
+func EndpointChannelPermission(cid, id string) string {
+	return EndpointChannel(cid) + "/permissions/" + id
+}
+
+func EndpointChannelFollowers(cid string) string { return EndpointChannel(cid) + "/followers" }
+
+func EndpointUserGuilds(uid string) string { return EndpointUser(uid) + "/guilds" }
+
+func EndpointGuild(gid string) string { return "/guilds/" + gid }
+
+func EndpointGuildPreview(gid string) string { return EndpointGuild(gid) + "/preview" }
+
+func EndpointGuildChannels(gid string) string { return EndpointGuild(gid) + "/channels" }
+
+func EndpointGuildRoles(gid string) string { return EndpointGuild(gid) + "/roles" }
+
+func EndpointGuildRole(gid, rid string) string { return EndpointGuildRoles(gid) + "/" + rid }
+
+func EndpointGuildMembers(gid string) string { return EndpointGuild(gid) + "/members" }
+
+func EndpointGuildMembersSearch(gid string) string { return EndpointGuildMembers(gid) + "/search" }
+
+func EndpointGuildMember(gid, uid string) string { return EndpointGuildMembers(gid) + "/" + uid }
+
+func EndpointGuildMemberRole(gid, uid, rid string) string {
+	return EndpointGuildMember(gid, uid) + "/roles/" + rid
+}
+
+func EndpointGuildBans(gid string) string { return EndpointGuild(gid) + "/bans" }
+
+func EndpointGuildBan(gid, uid string) string { return EndpointGuildBans(gid) + "/" + uid }
+
+func EndpointGuildPrune(gid string) string { return EndpointGuild(gid) + "/prune" }
+
+func EndpointGuildAuditLogs(gid string) string { return EndpointGuild(gid) + "/audit-logs" }
+
+func EndpointChannelWebhooks(cid string) string { return EndpointChannel(cid) + "/webhooks" }
+
+func EndpointGuildWebhooks(gid string) string { return EndpointGuild(gid) + "/webhooks" }
+
+func EndpointWebhook(id string) string { return "/webhooks/" + id }
+
+func EndpointWebhookToken(id, token string) string { return EndpointWebhook(id) + "/" + token }
+
+func EndpointWebhookMessage(id, token, mid string) string {
+	return EndpointWebhookToken(id, token) + "/messages/" + mid
+}
+
+func EndpointInteractionCallback(id, token string) string {
+	return "/interactions/" + id + "/" + token + "/callback"
+}
+
+func EndpointApplicationCommands(app string) string { return "/applications/" + app + "/commands" }
+
+func EndpointApplicationGuildCommands(app, gid string) string {
+	return "/applications/" + app + "/guilds/" + gid + "/commands"
+}
+
+func EndpointApplicationCommandPermissions(app, gid, id string) string {
+	return EndpointApplicationGuildCommands(app, gid) + "/" + id + "/permissions"
+}
 
 const EndpointGateway = "/gateway"
 
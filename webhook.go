@@ -0,0 +1,221 @@
+package dgo2poc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pkg/errors"
+)
+
+// Client for a single webhook, authenticated by its token rather than a bot's.
+// Shares its RateLimiter and error types with Client; pass the same RateLimiter to both with
+// WithRateLimiter() to share rate limits between them.
+type WebhookClient interface {
+	// Returns the webhook.
+	Webhook(ctx context.Context) (*discordgo.Webhook, error)
+
+	// Sends a message through the webhook, and returns it. Use SendWithUsername() and
+	// SendWithAvatarURL() to override the webhook's name and avatar.
+	Execute(ctx context.Context, content string, opts ...SendOpt) (*discordgo.Message, error)
+
+	// Returns a message previously sent through the webhook.
+	Message(ctx context.Context, id string) (*discordgo.Message, error)
+
+	// Edits a message previously sent through the webhook.
+	MessageEdit(ctx context.Context, id string, opts ...EditOpt) (*discordgo.Message, error)
+
+	// Deletes a message previously sent through the webhook.
+	MessageDelete(ctx context.Context, id string) error
+
+	// Returns a WebhookClient that sends, edits, etc. messages in a thread in the webhook's channel.
+	InThread(thread string) WebhookClient
+
+	// Deletes the webhook.
+	Delete(ctx context.Context) error
+}
+
+type webhookClient struct {
+	*client
+
+	ID       string
+	WebToken string
+	ThreadID string
+}
+
+// Create a client for a webhook, from its ID and token.
+func NewWebhookClient(id, token string, opts ...ReqOption) WebhookClient {
+	return &webhookClient{
+		client: &client{
+			HTTPClient:  &http.Client{},
+			BaseURL:     BaseURL,
+			Opts:        opts,
+			RateLimiter: NewRateLimiter(),
+		},
+		ID:       id,
+		WebToken: token,
+	}
+}
+
+// Create a client for a webhook, from its URL, eg. "https://discord.com/api/webhooks/1234/abcd".
+func NewWebhookClientFromURL(urlStr string, opts ...ReqOption) (WebhookClient, error) {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i, part := range parts {
+		if part == "webhooks" && len(parts) == i+3 {
+			return NewWebhookClient(parts[i+1], parts[i+2], opts...), nil
+		}
+	}
+	return nil, errors.Errorf("not a webhook URL: %s", urlStr)
+}
+
+func (c *webhookClient) Webhook(ctx context.Context) (*discordgo.Webhook, error) {
+	var hook discordgo.Webhook
+	return &hook, c.RequestJSON(ctx, "GET", c.BaseURL+EndpointWebhookToken(c.ID, c.WebToken), nil, &hook)
+}
+
+func (c *webhookClient) Execute(ctx context.Context, content string, opts ...SendOpt) (*discordgo.Message, error) {
+	send := newMessageSend(content, opts)
+	send.Attachments = append(send.Attachments, fileAttachments(send.Files)...)
+
+	// Without wait=true, Discord doesn't return the message, or tell us if sending it failed.
+	q := url.Values{"wait": {"true"}}
+	var msg discordgo.Message
+	return &msg, c.requestMessage(ctx, "POST", c.url(EndpointWebhookToken(c.ID, c.WebToken), q), send, send.Files, &msg)
+}
+
+func (c *webhookClient) Message(ctx context.Context, mid string) (*discordgo.Message, error) {
+	var msg discordgo.Message
+	return &msg, c.RequestJSON(ctx, "GET", c.url(EndpointWebhookMessage(c.ID, c.WebToken, mid), nil), nil, &msg)
+}
+
+func (c *webhookClient) MessageEdit(ctx context.Context, mid string, opts ...EditOpt) (*discordgo.Message, error) {
+	edit := newMessageEdit(opts)
+	var msg discordgo.Message
+	return &msg, c.requestMessage(ctx, "PATCH", c.url(EndpointWebhookMessage(c.ID, c.WebToken, mid), nil), edit, edit.Files, &msg)
+}
+
+func (c *webhookClient) MessageDelete(ctx context.Context, mid string) error {
+	_, err := c.Request(ctx, "DELETE", c.url(EndpointWebhookMessage(c.ID, c.WebToken, mid), nil), nil)
+	return err
+}
+
+func (c *webhookClient) InThread(thread string) WebhookClient {
+	cc := *c
+	cc.ThreadID = thread
+	return &cc
+}
+
+func (c *webhookClient) Delete(ctx context.Context) error {
+	_, err := c.Request(ctx, "DELETE", c.BaseURL+EndpointWebhookToken(c.ID, c.WebToken), nil)
+	return err
+}
+
+// Returns the URL for an endpoint, with the given query and thread ID (if any).
+func (c *webhookClient) url(endpoint string, q url.Values) string {
+	if c.ThreadID != "" {
+		if q == nil {
+			q = url.Values{}
+		}
+		q.Set("thread_id", c.ThreadID)
+	}
+	if len(q) == 0 {
+		return c.BaseURL + endpoint
+	}
+	return c.BaseURL + endpoint + "?" + q.Encode()
+}
+
+// Options for Client.WebhookCreate() and Client.WebhookEdit().
+type WebhookParams struct {
+	Name      string  `json:"name,omitempty"`
+	Avatar    *string `json:"-"`
+	ChannelID string  `json:"channel_id,omitempty"`
+
+	// Reason shown in the guild's audit log.
+	Reason string `json:"-"`
+}
+
+func (p WebhookParams) MarshalJSON() ([]byte, error) {
+	type webhookParams WebhookParams
+	return json.Marshal(struct {
+		webhookParams
+		Avatar json.RawMessage `json:"avatar,omitempty"`
+	}{webhookParams(p), nullString(p.Avatar)})
+}
+
+// Options for Client.WebhookCreate() and Client.WebhookEdit().
+type WebhookOpt func(params *WebhookParams)
+
+// Set a webhook's default name.
+func WebhookWithName(name string) WebhookOpt {
+	return WebhookOpt(func(params *WebhookParams) {
+		params.Name = name
+	})
+}
+
+// Set a webhook's default avatar, as a data URI, eg. "data:image/png;base64,...". Use "" to remove it.
+func WebhookWithAvatar(avatar string) WebhookOpt {
+	return WebhookOpt(func(params *WebhookParams) {
+		params.Avatar = &avatar
+	})
+}
+
+// Move a webhook to another channel. Only works with Client.WebhookEdit().
+func WebhookWithChannel(channel string) WebhookOpt {
+	return WebhookOpt(func(params *WebhookParams) {
+		params.ChannelID = channel
+	})
+}
+
+// Set the reason shown in the guild's audit log.
+func WebhookWithReason(reason string) WebhookOpt {
+	return WebhookOpt(func(params *WebhookParams) {
+		params.Reason = reason
+	})
+}
+
+func (c *client) Webhook(ctx context.Context, id string) (*discordgo.Webhook, error) {
+	var hook discordgo.Webhook
+	return &hook, c.RequestJSON(ctx, "GET", c.BaseURL+EndpointWebhook(id), nil, &hook)
+}
+
+func (c *client) ChannelWebhooks(ctx context.Context, cid string) ([]*discordgo.Webhook, error) {
+	var hooks []*discordgo.Webhook
+	return hooks, c.RequestJSON(ctx, "GET", c.BaseURL+EndpointChannelWebhooks(cid), nil, &hooks)
+}
+
+func (c *client) GuildWebhooks(ctx context.Context, gid string) ([]*discordgo.Webhook, error) {
+	var hooks []*discordgo.Webhook
+	return hooks, c.RequestJSON(ctx, "GET", c.BaseURL+EndpointGuildWebhooks(gid), nil, &hooks)
+}
+
+func (c *client) WebhookCreate(ctx context.Context, cid, name string, opts ...WebhookOpt) (*discordgo.Webhook, error) {
+	return c.requestWebhook(ctx, "POST", c.BaseURL+EndpointChannelWebhooks(cid), WebhookParams{Name: name}, opts)
+}
+
+func (c *client) WebhookEdit(ctx context.Context, id string, opts ...WebhookOpt) (*discordgo.Webhook, error) {
+	return c.requestWebhook(ctx, "PATCH", c.BaseURL+EndpointWebhook(id), WebhookParams{}, opts)
+}
+
+func (c *client) requestWebhook(ctx context.Context, method, urlStr string, params WebhookParams, opts []WebhookOpt) (*discordgo.Webhook, error) {
+	for _, opt := range opts {
+		opt(&params)
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	var hook discordgo.Webhook
+	return &hook, c.RequestJSON(ctx, method, urlStr, data, &hook, WithAuditLogReason(params.Reason))
+}
+
+func (c *client) WebhookDelete(ctx context.Context, id, reason string) error {
+	_, err := c.Request(ctx, "DELETE", c.BaseURL+EndpointWebhook(id), nil, WithAuditLogReason(reason))
+	return err
+}
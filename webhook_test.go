@@ -0,0 +1,162 @@
+package dgo2poc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Returns a WebhookClient pointed at a test server.
+func newTestWebhookClient(t *testing.T, res string) (WebhookClient, *testRecorder, func()) {
+	srv, _, rec := newTestServer(t, res)
+	hook := NewWebhookClient("1234", "abcd").(*webhookClient)
+	hook.BaseURL = srv.URL
+	return hook, rec, srv.Close
+}
+
+func TestNewWebhookClientFromURL(t *testing.T) {
+	for _, urlStr := range []string{
+		"https://discord.com/api/webhooks/1234/abcd",
+		"https://canary.discord.com/api/v10/webhooks/1234/abcd",
+		"https://discordapp.com/api/webhooks/1234/abcd/",
+	} {
+		t.Run(urlStr, func(t *testing.T) {
+			hook, err := NewWebhookClientFromURL(urlStr)
+			require.NoError(t, err)
+			assert.Equal(t, "1234", hook.(*webhookClient).ID)
+			assert.Equal(t, "abcd", hook.(*webhookClient).WebToken)
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := NewWebhookClientFromURL("https://discord.com/api/channels/1234/messages")
+		assert.EqualError(t, err, "not a webhook URL: https://discord.com/api/channels/1234/messages")
+	})
+}
+
+func TestWebhookClientExecute(t *testing.T) {
+	hook, rec, done := newTestWebhookClient(t, `{"id":"5678","content":"hi"}`)
+	defer done()
+
+	msg, err := hook.Execute(context.Background(), "hi",
+		SendWithUsername("Bot"),
+		SendWithAvatarURL("https://example.com/avatar.png"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "5678", msg.ID)
+	assert.Equal(t, "POST", rec.Last().Method)
+	assert.Equal(t, "/webhooks/1234/abcd", rec.Last().Path)
+	assert.Equal(t, "wait=true", rec.Last().Query)
+	assert.Equal(t, "", rec.Last().Header.Get("Authorization"))
+	assert.JSONEq(t, `{
+		"content": "hi",
+		"tts": false,
+		"embeds": null,
+		"components": null,
+		"username": "Bot",
+		"avatar_url": "https://example.com/avatar.png"
+	}`, rec.Last().Body)
+
+	t.Run("File", func(t *testing.T) {
+		_, err := hook.Execute(context.Background(), "hi", SendWithFile("test.txt", strings.NewReader("hello")))
+		require.NoError(t, err)
+		assert.Contains(t, rec.Last().Header.Get("Content-Type"), "multipart/form-data")
+		assert.Contains(t, rec.Last().Body, "hello")
+	})
+
+	t.Run("Thread", func(t *testing.T) {
+		_, err := hook.InThread("9999").Execute(context.Background(), "hi")
+		require.NoError(t, err)
+		assert.Equal(t, "thread_id=9999&wait=true", rec.Last().Query)
+	})
+
+	t.Run("Forum", func(t *testing.T) {
+		_, err := hook.Execute(context.Background(), "hi", SendWithThreadName("New Post"))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"content":"hi","tts":false,"embeds":null,"components":null,"thread_name":"New Post"}`, rec.Last().Body)
+	})
+}
+
+func TestWebhookClientMessages(t *testing.T) {
+	hook, rec, done := newTestWebhookClient(t, `{"id":"5678"}`)
+	defer done()
+	ctx := context.Background()
+
+	_, err := hook.Message(ctx, "5678")
+	require.NoError(t, err)
+	assert.Equal(t, "GET", rec.Last().Method)
+	assert.Equal(t, "/webhooks/1234/abcd/messages/5678", rec.Last().Path)
+
+	_, err = hook.MessageEdit(ctx, "5678", EditWithContent("edited"))
+	require.NoError(t, err)
+	assert.Equal(t, "PATCH", rec.Last().Method)
+	assert.Equal(t, "/webhooks/1234/abcd/messages/5678", rec.Last().Path)
+	assert.JSONEq(t, `{"content":"edited"}`, rec.Last().Body)
+
+	require.NoError(t, hook.InThread("9999").MessageDelete(ctx, "5678"))
+	assert.Equal(t, "DELETE", rec.Last().Method)
+	assert.Equal(t, "/webhooks/1234/abcd/messages/5678", rec.Last().Path)
+	assert.Equal(t, "thread_id=9999", rec.Last().Query)
+}
+
+func TestWebhookClientWebhook(t *testing.T) {
+	hook, rec, done := newTestWebhookClient(t, `{"id":"1234","name":"Hook"}`)
+	defer done()
+	ctx := context.Background()
+
+	info, err := hook.Webhook(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "/webhooks/1234/abcd", rec.Last().Path)
+	assert.Equal(t, "Hook", info.Name)
+
+	require.NoError(t, hook.Delete(ctx))
+	assert.Equal(t, "DELETE", rec.Last().Method)
+	assert.Equal(t, "/webhooks/1234/abcd", rec.Last().Path)
+}
+
+func TestClientWebhooks(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `{"id":"1234","token":"abcd"}`)
+	defer srv.Close()
+	ctx := context.Background()
+
+	hook, err := cl.WebhookCreate(ctx, "5678", "Hook", WebhookWithReason("alerts"))
+	require.NoError(t, err)
+	assert.Equal(t, "POST", rec.Last().Method)
+	assert.Equal(t, "/channels/5678/webhooks", rec.Last().Path)
+	assert.Equal(t, "alerts", rec.Last().Header.Get("X-Audit-Log-Reason"))
+	assert.JSONEq(t, `{"name":"Hook"}`, rec.Last().Body)
+	assert.Equal(t, "abcd", hook.Token)
+
+	_, err = cl.WebhookEdit(ctx, "1234", WebhookWithChannel("9999"), WebhookWithAvatar(""))
+	require.NoError(t, err)
+	assert.Equal(t, "PATCH", rec.Last().Method)
+	assert.Equal(t, "/webhooks/1234", rec.Last().Path)
+	assert.JSONEq(t, `{"channel_id":"9999","avatar":null}`, rec.Last().Body)
+
+	_, err = cl.Webhook(ctx, "1234")
+	require.NoError(t, err)
+	assert.Equal(t, "/webhooks/1234", rec.Last().Path)
+
+	require.NoError(t, cl.WebhookDelete(ctx, "1234", ""))
+	assert.Equal(t, "DELETE", rec.Last().Method)
+	assert.Equal(t, "/webhooks/1234", rec.Last().Path)
+}
+
+func TestClientWebhooksList(t *testing.T) {
+	srv, cl, rec := newTestServer(t, `[{"id":"1234"}]`)
+	defer srv.Close()
+	ctx := context.Background()
+
+	hooks, err := cl.ChannelWebhooks(ctx, "5678")
+	require.NoError(t, err)
+	assert.Equal(t, "/channels/5678/webhooks", rec.Last().Path)
+	assert.Len(t, hooks, 1)
+
+	hooks, err = cl.GuildWebhooks(ctx, "9999")
+	require.NoError(t, err)
+	assert.Equal(t, "/guilds/9999/webhooks", rec.Last().Path)
+	assert.Len(t, hooks, 1)
+}
@@ -16,3 +16,12 @@ type Ready struct {
 type GuildCreate struct {
 	discordgo.Guild // borrowing this definition for a bit
 }
+
+type GuildAuditLogEntryCreate struct {
+	discordgo.AuditLogEntry
+	GuildID string `json:"guild_id"`
+}
+
+type InteractionCreate struct {
+	discordgo.Interaction
+}
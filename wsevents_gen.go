@@ -12,9 +12,15 @@ import (
 )
 
 type wsHandlers struct {
+	GuildAuditLogEntryCreate     []*func(ctx context.Context, ev *GuildAuditLogEntryCreate)
+	GuildAuditLogEntryCreateLock sync.RWMutex
+
 	GuildCreate     []*func(ctx context.Context, ev *GuildCreate)
 	GuildCreateLock sync.RWMutex
 
+	InteractionCreate     []*func(ctx context.Context, ev *InteractionCreate)
+	InteractionCreateLock sync.RWMutex
+
 	Ready     []*func(ctx context.Context, ev *Ready)
 	ReadyLock sync.RWMutex
 }
@@ -40,6 +46,20 @@ func (h *wsHandlers) Add(hls ...wsHandler) func() {
 	}
 }
 
+func (hls *wsHandlers) DispatchGuildAuditLogEntryCreate(ctx context.Context, ev *GuildAuditLogEntryCreate, sync bool) {
+	hls.GuildAuditLogEntryCreateLock.RLock()
+	fns := hls.GuildAuditLogEntryCreate
+	hls.GuildAuditLogEntryCreateLock.RUnlock()
+	for _, ptr := range fns {
+		fn := *ptr
+		if sync {
+			fn(ctx, ev)
+		} else {
+			go fn(ctx, ev)
+		}
+	}
+}
+
 func (hls *wsHandlers) DispatchGuildCreate(ctx context.Context, ev *GuildCreate, sync bool) {
 	hls.GuildCreateLock.RLock()
 	fns := hls.GuildCreate
@@ -54,6 +74,20 @@ func (hls *wsHandlers) DispatchGuildCreate(ctx context.Context, ev *GuildCreate,
 	}
 }
 
+func (hls *wsHandlers) DispatchInteractionCreate(ctx context.Context, ev *InteractionCreate, sync bool) {
+	hls.InteractionCreateLock.RLock()
+	fns := hls.InteractionCreate
+	hls.InteractionCreateLock.RUnlock()
+	for _, ptr := range fns {
+		fn := *ptr
+		if sync {
+			fn(ctx, ev)
+		} else {
+			go fn(ctx, ev)
+		}
+	}
+}
+
 func (hls *wsHandlers) DispatchReady(ctx context.Context, ev *Ready, sync bool) {
 	hls.ReadyLock.RLock()
 	fns := hls.Ready
@@ -70,6 +104,13 @@ func (hls *wsHandlers) DispatchReady(ctx context.Context, ev *Ready, sync bool)
 
 func dispatch(ctx context.Context, t string, data []byte, pre, main *wsHandlers) error {
 	switch t {
+	case "GUILD_AUDIT_LOG_ENTRY_CREATE":
+		var ev GuildAuditLogEntryCreate
+		if err := json.Unmarshal(data, &ev); err != nil {
+			return errors.Wrap(err, t)
+		}
+		pre.DispatchGuildAuditLogEntryCreate(ctx, &ev, true)
+		main.DispatchGuildAuditLogEntryCreate(ctx, &ev, false)
 	case "GUILD_CREATE":
 		var ev GuildCreate
 		if err := json.Unmarshal(data, &ev); err != nil {
@@ -77,6 +118,13 @@ func dispatch(ctx context.Context, t string, data []byte, pre, main *wsHandlers)
 		}
 		pre.DispatchGuildCreate(ctx, &ev, true)
 		main.DispatchGuildCreate(ctx, &ev, false)
+	case "INTERACTION_CREATE":
+		var ev InteractionCreate
+		if err := json.Unmarshal(data, &ev); err != nil {
+			return errors.Wrap(err, t)
+		}
+		pre.DispatchInteractionCreate(ctx, &ev, true)
+		main.DispatchInteractionCreate(ctx, &ev, false)
 	case "READY":
 		var ev Ready
 		if err := json.Unmarshal(data, &ev); err != nil {
@@ -88,6 +136,24 @@ func dispatch(ctx context.Context, t string, data []byte, pre, main *wsHandlers)
 	return nil
 }
 
+// Handle a GuildAuditLogEntryCreate event. See WSClient.AddHandler().
+func OnGuildAuditLogEntryCreate(fn func(ctx context.Context, ev *GuildAuditLogEntryCreate)) wsHandler {
+	return wsHandler(func(hls *wsHandlers) func() {
+		hls.GuildAuditLogEntryCreateLock.Lock()
+		hls.GuildAuditLogEntryCreate = append(hls.GuildAuditLogEntryCreate, &fn)
+		hls.GuildAuditLogEntryCreateLock.Unlock()
+		return func() {
+			hls.GuildAuditLogEntryCreateLock.Lock()
+			for i, v := range hls.GuildAuditLogEntryCreate {
+				if v == &fn {
+					hls.GuildAuditLogEntryCreate = append(hls.GuildAuditLogEntryCreate[:i], hls.GuildAuditLogEntryCreate[i+1:]...)
+				}
+			}
+			hls.GuildAuditLogEntryCreateLock.Unlock()
+		}
+	})
+}
+
 // Handle a GuildCreate event. See WSClient.AddHandler().
 func OnGuildCreate(fn func(ctx context.Context, ev *GuildCreate)) wsHandler {
 	return wsHandler(func(hls *wsHandlers) func() {
@@ -106,6 +172,24 @@ func OnGuildCreate(fn func(ctx context.Context, ev *GuildCreate)) wsHandler {
 	})
 }
 
+// Handle a InteractionCreate event. See WSClient.AddHandler().
+func OnInteractionCreate(fn func(ctx context.Context, ev *InteractionCreate)) wsHandler {
+	return wsHandler(func(hls *wsHandlers) func() {
+		hls.InteractionCreateLock.Lock()
+		hls.InteractionCreate = append(hls.InteractionCreate, &fn)
+		hls.InteractionCreateLock.Unlock()
+		return func() {
+			hls.InteractionCreateLock.Lock()
+			for i, v := range hls.InteractionCreate {
+				if v == &fn {
+					hls.InteractionCreate = append(hls.InteractionCreate[:i], hls.InteractionCreate[i+1:]...)
+				}
+			}
+			hls.InteractionCreateLock.Unlock()
+		}
+	})
+}
+
 // Handle a Ready event. See WSClient.AddHandler().
 func OnReady(fn func(ctx context.Context, ev *Ready)) wsHandler {
 	return wsHandler(func(hls *wsHandlers) func() {